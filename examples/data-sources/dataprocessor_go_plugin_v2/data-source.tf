@@ -0,0 +1,86 @@
+locals {
+  # For readability this should go on a .go file and load
+  # with https://www.terraform.io/language/functions/file.
+  # We added here to be present in the docs example.
+  filter_users_plugin = <<EOT
+package tf
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// ProcessorPluginV2 Will take a list of users as input and will filter
+// them by a regex against its username, it will return the list again
+// without the ones that matched limited to the max number of users.
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	// Get filter regex.
+	regexOpt, _ := vars["username_filter"].(string)
+	if regexOpt == "" {
+		regexOpt = ".*"
+	}
+	regex, err := regexp.Compile(regexOpt)
+	if err != nil {
+		return nil, fmt.Errorf("regex %q could not be compiled: %w", regexOpt, err)
+	}
+
+	// JSON numbers are decoded as float64.
+	maxUsers := int(vars["max_users"].(float64))
+
+	users, ok := input.([]any)
+	if !ok {
+		return nil, fmt.Errorf("input should be a list of users")
+	}
+
+	// Filter users if no match and sort result.
+	resultUsers := []map[string]any{}
+	for _, u := range users {
+		user := u.(map[string]any)
+		if !regex.MatchString(user["username"].(string)) {
+			resultUsers = append(resultUsers, user)
+		}
+	}
+	sort.SliceStable(resultUsers, func(i, j int) bool { return resultUsers[i]["age"].(float64) < resultUsers[j]["age"].(float64) })
+
+	if len(resultUsers) > maxUsers {
+		resultUsers = resultUsers[:maxUsers]
+	}
+
+	return resultUsers, nil
+}
+  EOT
+
+  users = [
+    {username = "good-user0", age = 30},
+    {username = "good-user1", age = 41},
+    {username = "good-user2", age = 52},
+    {username = "bad-user3",  age = 63},
+    {username = "good-user4", age = 74},
+    {username = "good-user5", age = 85},
+    {username = "bad-user6",  age = 96},
+    {username = "good-user7", age = 17},
+    {username = "bad-user8",  age = 28},
+    {username = "bad-user9",  age = 09},
+  ]
+
+  filtered_sorted_users = jsondecode(data.dataprocessor_go_plugin_v2.test.result)
+}
+
+# In this example, we are filtering users with a regex, sorting the result by age
+# and limiting the number of users.
+data "dataprocessor_go_plugin_v2" "test" {
+  input_data = jsonencode(local.users)
+  plugin = local.filter_users_plugin
+  vars = {
+    "username_filter" = "^bad-user\\d$"
+  }
+  json_vars = {
+    "max_users" = jsonencode(3)
+  }
+}
+
+output "test" {
+  value = local.filtered_sorted_users
+}
@@ -0,0 +1,52 @@
+# In this example, we get the adult users with JQ, we anonymize their names with a Go plugin
+# and we render them in YAML with YQ, in a single data source.
+locals {
+  users = [
+    { name = "Alice", age = 31 },
+    { name = "Bob", age = 17 },
+    { name = "Carol", age = 45 },
+  ]
+
+  anonymize_plugin = <<EOT
+package tf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	users := input.([]any)
+	for _, u := range users {
+		user := u.(map[string]any)
+		h := sha256.Sum256([]byte(user["name"].(string)))
+		user["name"] = hex.EncodeToString(h[:])[:8]
+	}
+	return users, nil
+}
+EOT
+}
+
+data "dataprocessor_pipeline" "adult_users" {
+  input = local.users
+
+  step {
+    type       = "jq"
+    expression = "map(select(.age >= 18))"
+  }
+
+  step {
+    type   = "go_plugin_v2"
+    plugin = local.anonymize_plugin
+  }
+
+  step {
+    type       = "yq"
+    expression = "... style=\"\""
+  }
+}
+
+output "adult_users" {
+  value = data.dataprocessor_pipeline.adult_users.result
+}
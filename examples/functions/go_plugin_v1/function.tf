@@ -0,0 +1,19 @@
+# In this example, we are executing a small Go plugin inline, without data sources.
+locals {
+  plugin = <<EOT
+package tfdataprocessor
+
+import (
+	"context"
+	"strings"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return strings.Repeat(inputData, len(vars["times"])), nil
+}
+EOT
+}
+
+output "test" {
+  value = provider::dataprocessor::go_plugin_v1(local.plugin, "ab", { times = "xxx" })
+}
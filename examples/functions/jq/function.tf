@@ -0,0 +1,14 @@
+# In this example, we are filtering a Terraform value inline, without data sources.
+locals {
+  report = {
+    timestamp = 1234567890
+    results = [
+      { name = "John", age = 43, city = "TownA" },
+      { name = "Joe", age = 10, city = "TownB" },
+    ]
+  }
+}
+
+output "test" {
+  value = provider::dataprocessor::jq("[.results[] | select(.age > 18) | .name]", local.report)
+}
@@ -0,0 +1,21 @@
+# In this example, we are getting the container images of a Kubernetes deployment YAML inline, without data sources.
+locals {
+  deployment = <<EOT
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: example/app:v1.0.0
+        - name: sidecar
+          image: example/sidecar:v2.1.0
+EOT
+}
+
+output "test" {
+  value = provider::dataprocessor::yq("[.spec.template.spec.containers[].image]", local.deployment)
+}
@@ -0,0 +1,27 @@
+terraform {
+  required_providers {
+    dataprocessor = {
+      source = "slok/dataprocessor"
+    }
+  }
+}
+
+locals {
+  users = [
+    {username = "user0", email = "user0@example.com", team = "platform"},
+    {username = "user1", email = "user1@example.com"},
+    {username = "user2", email = "user2", team = "platform"},
+    {username = "",      email = "user3@example.com", team = "product"},
+  ]
+}
+
+# In this example, every problem of the users is reported as its own Terraform diagnostic pointing
+# to the user, the invalid users are errors (and fail the execution) and the missing teams are warnings.
+data "dataprocessor_go_plugin_v1" "validate_users" {
+  input  = local.users
+  plugin = file("./plugin.go")
+}
+
+output "users_findings" {
+  value = data.dataprocessor_go_plugin_v1.validate_users.findings
+}
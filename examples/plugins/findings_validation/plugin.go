@@ -0,0 +1,60 @@
+package tf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+type User struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Team     string `json:"team"`
+}
+
+// ProcessorPluginV1 will check the users, every problem is reported as a finding instead of failing
+// with a single error, so Terraform shows each one as its own error or warning.
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	users := []User{}
+	err := json.Unmarshal([]byte(inputData), &users)
+	if err != nil {
+		return "", fmt.Errorf("could not unmarshal users: %w", err)
+	}
+
+	for i, u := range users {
+		path := fmt.Sprintf("[%d]", i)
+
+		if u.Username == "" {
+			dataprocessor.AddFinding(ctx, dataprocessor.Finding{
+				Severity: dataprocessor.SeverityError,
+				Summary:  "Missing username",
+				Detail:   "every user requires a username",
+				Path:     path + ".username",
+			})
+		}
+
+		if !strings.Contains(u.Email, "@") {
+			dataprocessor.AddFinding(ctx, dataprocessor.Finding{
+				Severity: dataprocessor.SeverityError,
+				Summary:  "Invalid email",
+				Detail:   fmt.Sprintf("%q is not a valid email", u.Email),
+				Path:     path + ".email",
+			})
+		}
+
+		// A missing team doesn't make the user invalid.
+		if u.Team == "" {
+			dataprocessor.AddFinding(ctx, dataprocessor.Finding{
+				Severity: dataprocessor.SeverityWarning,
+				Summary:  "Missing team",
+				Detail:   fmt.Sprintf("user %q doesn't have a team", u.Username),
+				Path:     path + ".team",
+			})
+		}
+	}
+
+	return "valid", nil
+}
@@ -3,21 +3,14 @@ terraform {
     dataprocessor = {
       source = "slok/dataprocessor"
     }
-    http = {
-      source = "hashicorp/http"
-    }
   }
 }
 
-// Get a shared remote plugin.
-data "http" "max_length_plugin" {
-  url = "https://raw.githubusercontent.com/slok/terraform-provider-dataprocessor/main/examples/plugins/simple_validation/plugin.go"
-}
-
-
+// Get a shared remote plugin, pinned to the reviewed source code checksum.
 data "dataprocessor_go_plugin_v1" "validate_max_length" {
-  plugin = data.http.max_length_plugin.response_body
-  input_data = "something123456"
+  plugin_url    = "https://raw.githubusercontent.com/slok/terraform-provider-dataprocessor/main/examples/plugins/simple_validation/plugin.go"
+  plugin_sha256 = "8edc62d4c7b3581ce1123c03bc7de8f2531123a729891721bae7693fd7f44625"
+  input_data    = "something123456"
   vars = {
     max_length = 10
   }
@@ -0,0 +1,44 @@
+# In this example, a plugin checks the files exist on disk, the check is only executed again when
+# the files list or the check trigger change, instead of on every plan.
+variable "check_id" {
+  type    = string
+  default = "1"
+}
+
+locals {
+  check_files_plugin = <<EOT
+package tf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	files := []string{}
+	err := json.Unmarshal([]byte(inputData), &files)
+	if err != nil {
+		return "", fmt.Errorf("could not unmarshal input into JSON: %w", err)
+	}
+
+	for _, f := range files {
+		_, err := os.Stat(f)
+		if err != nil {
+			return "", fmt.Errorf("file %q is missing: %w", f, err)
+		}
+	}
+
+	return "ok", nil
+}
+EOT
+}
+
+resource "dataprocessor_go_plugin_v1" "check_files" {
+  plugin = local.check_files_plugin
+  input  = ["/etc/hosts", "/etc/resolv.conf"]
+  triggers = {
+    check = var.check_id
+  }
+}
@@ -0,0 +1,18 @@
+# In this example, the users are grouped by team only when the users list changes, the result is
+# stored in the state and it's not calculated again on every plan.
+locals {
+  users = [
+    { name = "alice", team = "platform" },
+    { name = "bob", team = "data" },
+    { name = "carol", team = "platform" },
+  ]
+}
+
+resource "dataprocessor_jq" "users_by_team" {
+  input      = local.users
+  expression = "group_by(.team) | map({(.[0].team): map(.name)}) | add"
+}
+
+output "platform_users" {
+  value = dataprocessor_jq.users_by_team.result_value.platform
+}
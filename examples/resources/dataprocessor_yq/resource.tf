@@ -0,0 +1,32 @@
+# In this example, the Kubernetes manifests are rendered with the image tag, they are only rendered
+# again when the manifests, the expression or the release trigger change.
+variable "release" {
+  type    = string
+  default = "v1.2.0"
+}
+
+resource "dataprocessor_yq" "manifests" {
+  input_data = <<EOT
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: example/app
+EOT
+  expression = ".spec.template.spec.containers[0].image = \"example/app:\" + $tag"
+  vars = {
+    tag = var.release
+  }
+  triggers = {
+    release = var.release
+  }
+}
+
+output "manifests" {
+  value = dataprocessor_yq.manifests.result
+}
@@ -0,0 +1,152 @@
+package fetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the timeout of the HTTP client used when the fetcher doesn't have one.
+	DefaultTimeout = 30 * time.Second
+	// MaxSize is the maximum size in bytes of the fetched data, the download of bigger data fails
+	// without reading all of it.
+	MaxSize = 5 * 1024 * 1024
+)
+
+// ChecksumMismatchError is returned when the fetched data doesn't match the expected checksum.
+type ChecksumMismatchError struct {
+	URL      string
+	Expected string
+	Got      string
+}
+
+func (c ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %q, expected sha256 %q, got %q", c.URL, c.Expected, c.Got)
+}
+
+// Fetcher knows how to fetch remote data verifying its checksum.
+type Fetcher interface {
+	Fetch(ctx context.Context, url, sha256Sum string) (data string, err error)
+}
+
+// FetcherFunc its a helper type to create Fetchers with a single function.
+type FetcherFunc func(ctx context.Context, url, sha256Sum string) (data string, err error)
+
+func (f FetcherFunc) Fetch(ctx context.Context, url, sha256Sum string) (data string, err error) {
+	return f(ctx, url, sha256Sum)
+}
+
+// NewHTTPFetcher returns a fetcher that downloads the data using HTTP and stores it in a local cache
+// indexed by its checksum, so the same data is only downloaded once.
+// If the cache dir is empty, the data will not be cached. If the client is nil, a client with the
+// default timeout will be used.
+func NewHTTPFetcher(client *http.Client, cacheDir string) Fetcher {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	return FetcherFunc(func(ctx context.Context, url, sha256Sum string) (string, error) {
+		sha256Sum = strings.ToLower(sha256Sum)
+
+		// Try with the cache first.
+		cachePath := ""
+		if cacheDir != "" {
+			cachePath = filepath.Join(cacheDir, sha256Sum)
+			data, err := os.ReadFile(cachePath)
+			if err == nil && checksum(data) == sha256Sum {
+				return string(data), nil
+			}
+		}
+
+		data, err := download(ctx, client, url)
+		if err != nil {
+			return "", err
+		}
+
+		// Never use data that doesn't match the checksum.
+		got := checksum(data)
+		if got != sha256Sum {
+			return "", ChecksumMismatchError{URL: url, Expected: sha256Sum, Got: got}
+		}
+
+		if cachePath != "" {
+			// The cache is an optimization, we don't fail if we can't store the data.
+			_ = storeCache(cacheDir, cachePath, data)
+		}
+
+		return string(data), nil
+	})
+}
+
+func download(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not download %q: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download %q: unexpected status code %d", url, resp.StatusCode)
+	}
+
+	if resp.ContentLength > MaxSize {
+		return nil, fmt.Errorf("could not download %q: the data is bigger than %d bytes", url, MaxSize)
+	}
+
+	// Don't trust the content length, read one more byte to know if the data is bigger than the max size.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q response: %w", url, err)
+	}
+
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("could not download %q: the data is bigger than %d bytes", url, MaxSize)
+	}
+
+	return data, nil
+}
+
+// storeCache stores the data atomically, so concurrent fetches never read partial data.
+func storeCache(cacheDir, cachePath string, data []byte) error {
+	err := os.MkdirAll(cacheDir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(cacheDir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(data)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), cachePath)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
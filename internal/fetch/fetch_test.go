@@ -0,0 +1,196 @@
+package fetch_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/fetch"
+)
+
+const (
+	testData       = "package testplugin\n"
+	testDataSHA256 = "4d1d416c8a2f1e2733c370ecbc179f26fad633456098fe21e341e1bb3698658b"
+)
+
+func TestHTTPFetcherFetch(t *testing.T) {
+	tests := map[string]struct {
+		statusCode   int
+		body         string
+		sha256       string
+		cachedData   string
+		noCache      bool
+		chunked      bool
+		expData      string
+		expRequests  int32
+		expErr       bool
+		expCacheData string
+	}{
+		"Data matching the checksum should be returned and cached.": {
+			statusCode:   http.StatusOK,
+			body:         testData,
+			sha256:       testDataSHA256,
+			expData:      testData,
+			expRequests:  1,
+			expCacheData: testData,
+		},
+
+		"The checksum should be case insensitive.": {
+			statusCode:   http.StatusOK,
+			body:         testData,
+			sha256:       "4D1D416C8A2F1E2733C370ECBC179F26FAD633456098FE21E341E1BB3698658B",
+			expData:      testData,
+			expRequests:  1,
+			expCacheData: testData,
+		},
+
+		"Data not matching the checksum should fail and not be cached.": {
+			statusCode:  http.StatusOK,
+			body:        "package evilplugin\n",
+			sha256:      testDataSHA256,
+			expRequests: 1,
+			expErr:      true,
+		},
+
+		"A non OK status code should fail.": {
+			statusCode:  http.StatusNotFound,
+			body:        testData,
+			sha256:      testDataSHA256,
+			expRequests: 1,
+			expErr:      true,
+		},
+
+		"Data bigger than the max size should fail and not be cached.": {
+			statusCode:  http.StatusOK,
+			body:        strings.Repeat("a", fetch.MaxSize+1),
+			sha256:      testDataSHA256,
+			expRequests: 1,
+			expErr:      true,
+		},
+
+		"Data bigger than the max size without content length should fail and not be cached.": {
+			statusCode:  http.StatusOK,
+			body:        strings.Repeat("a", fetch.MaxSize+1),
+			sha256:      testDataSHA256,
+			chunked:     true,
+			expRequests: 1,
+			expErr:      true,
+		},
+
+		"Cached data should be returned without downloading.": {
+			statusCode:   http.StatusOK,
+			body:         testData,
+			sha256:       testDataSHA256,
+			cachedData:   testData,
+			expData:      testData,
+			expRequests:  0,
+			expCacheData: testData,
+		},
+
+		"Cached data not matching the checksum should be ignored and downloaded again.": {
+			statusCode:   http.StatusOK,
+			body:         testData,
+			sha256:       testDataSHA256,
+			cachedData:   "package evilplugin\n",
+			expData:      testData,
+			expRequests:  1,
+			expCacheData: testData,
+		},
+
+		"Without cache the data should be downloaded.": {
+			statusCode:  http.StatusOK,
+			body:        testData,
+			sha256:      testDataSHA256,
+			noCache:     true,
+			expData:     testData,
+			expRequests: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var requests int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				if test.chunked {
+					// Without content length the response is chunked.
+					w.Header().Set("Transfer-Encoding", "chunked")
+				} else {
+					w.Header().Set("Content-Length", strconv.Itoa(len(test.body)))
+				}
+				w.WriteHeader(test.statusCode)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer srv.Close()
+
+			cacheDir := t.TempDir()
+			if test.cachedData != "" {
+				err := os.WriteFile(filepath.Join(cacheDir, testDataSHA256), []byte(test.cachedData), 0o644)
+				require.NoError(err)
+			}
+			if test.noCache {
+				cacheDir = ""
+			}
+
+			f := fetch.NewHTTPFetcher(srv.Client(), cacheDir)
+			gotData, err := f.Fetch(context.TODO(), srv.URL+"/plugin.go", test.sha256)
+
+			assert.Equal(test.expRequests, atomic.LoadInt32(&requests))
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expData, gotData)
+			}
+
+			if test.expCacheData != "" {
+				data, err := os.ReadFile(filepath.Join(cacheDir, testDataSHA256))
+				require.NoError(err)
+				assert.Equal(test.expCacheData, string(data))
+			} else if cacheDir != "" {
+				_, err := os.Stat(filepath.Join(cacheDir, testDataSHA256))
+				assert.True(os.IsNotExist(err))
+			}
+		})
+	}
+}
+
+func TestHTTPFetcherFetchContext(t *testing.T) {
+	assert := assert.New(t)
+
+	// The server never finishes the response.
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(testData))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	f := fetch.NewHTTPFetcher(srv.Client(), "")
+	_, err := f.Fetch(ctx, srv.URL+"/plugin.go", testDataSHA256)
+
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Less(time.Since(start), 5*time.Second)
+}
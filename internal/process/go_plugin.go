@@ -0,0 +1,137 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/traefik/yaegi/interp"
+)
+
+var packageRegexp = regexp.MustCompile(`(?m)^package +([^\s]+) *$`)
+
+// loadRawPlugin loads the plugin source code and returns the plugin function symbol, the plugin standard
+// output and error are written on stdout and stderr.
+func loadRawPlugin(ctx context.Context, src string, sandbox GoPluginSandbox, funcName string, stdout, stderr io.Writer) (*interp.Interpreter, reflect.Value, error) {
+	// Load the plugin in a new interpreter.
+	// For each plugin we need to use an independent interpreter to avoid name collisions.
+	yaegiInterp, err := newYaeginInterpreter(src, sandbox, stdout, stderr)
+	if err != nil {
+		return nil, reflect.Value{}, fmt.Errorf("could not create a new Yaegi interpreter: %w", err)
+	}
+
+	_, err = yaegiInterp.EvalWithContext(ctx, src)
+	if err != nil {
+		return nil, reflect.Value{}, fmt.Errorf("could not evaluate plugin source code: %w", positionedSourceError(src, err))
+	}
+
+	// Discover package name.
+	packageMatch := packageRegexp.FindStringSubmatch(src)
+	if len(packageMatch) != 2 {
+		return nil, reflect.Value{}, fmt.Errorf("invalid plugin source code, could not get package name")
+	}
+	packageName := packageMatch[1]
+
+	// Get plugin logic.
+	pluginFunc, err := yaegiInterp.EvalWithContext(ctx, fmt.Sprintf("%s.%s", packageName, funcName))
+	if err != nil {
+		return nil, reflect.Value{}, fmt.Errorf("could not get plugin: %w", err)
+	}
+
+	return yaegiInterp, pluginFunc, nil
+}
+
+// stopInterpreterOnDone will stop the code running on the interpreter when the context is done.
+// The returned release function must be called when the execution finishes, after that, the
+// stopped function will tell if the interpreter was stopped.
+func stopInterpreterOnDone(ctx context.Context, i *interp.Interpreter) (stopped func() bool, release func()) {
+	var isStopped atomic.Bool
+	finished := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-finished:
+		case <-ctx.Done():
+			stopInterpreter(i)
+			isStopped.Store(true)
+		}
+	}()
+
+	release = func() {
+		close(finished)
+		<-done
+	}
+
+	return isStopped.Load, release
+}
+
+// stopInterpreter stops all the code running on the interpreter.
+//
+// Yaegi only stops the running code when the context of an `EvalWithContext` is cancelled, so
+// we evaluate a noop with a cancelled context until Yaegi takes the cancellation path (if the noop
+// finishes first, Yaegi could ignore the context).
+func stopInterpreter(i *interp.Interpreter) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for {
+		_, err := i.EvalWithContext(ctx, "0")
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+	}
+}
+
+func newYaeginInterpreter(src string, sandbox GoPluginSandbox, stdout, stderr io.Writer) (*interp.Interpreter, error) {
+	symbols, err := sandbox.symbols()
+	if err != nil {
+		return nil, fmt.Errorf("invalid sandbox: %w", err)
+	}
+
+	// Fail fast with a clear error instead of the Yaegi missing package error.
+	err = sandbox.checkImports(src, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	// The plugins don't have input, they read an empty standard input instead of the provider one.
+	var stdin io.Reader = strings.NewReader("")
+	i := interp.New(interp.Options{Stdin: stdin, Stdout: stdout, Stderr: stderr})
+	err = i.Use(symbols)
+	if err != nil {
+		return nil, fmt.Errorf("could not use stdlib symbols: %w", err)
+	}
+
+	// Yaegi only redirects the `os` standard streams to the interpreter ones when they are files, the
+	// plugins use them as writers instead.
+	if osSymbols, ok := symbols["os/os"]; ok {
+		stdio := map[string]reflect.Value{}
+		if _, ok := osSymbols["Stdin"]; ok {
+			stdio["Stdin"] = reflect.ValueOf(&stdin).Elem()
+		}
+		if _, ok := osSymbols["Stdout"]; ok {
+			stdio["Stdout"] = reflect.ValueOf(&stdout).Elem()
+		}
+		if _, ok := osSymbols["Stderr"]; ok {
+			stdio["Stderr"] = reflect.ValueOf(&stderr).Elem()
+		}
+
+		err = i.Use(interp.Exports{"os/os": stdio})
+		if err != nil {
+			return nil, fmt.Errorf("could not use stdio symbols: %w", err)
+		}
+	}
+
+	err = i.Use(pluginAPISymbols)
+	if err != nil {
+		return nil, fmt.Errorf("could not use plugin API symbols: %w", err)
+	}
+
+	return i, nil
+}
@@ -0,0 +1,210 @@
+package process
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/traefik/yaegi/interp"
+)
+
+const (
+	// goPluginCacheMaxPlugins is the maximum number of plugins on the cache, when it's full the least
+	// recently used plugin is evicted.
+	goPluginCacheMaxPlugins = 64
+	// goPluginPoolMaxIdle is the maximum number of idle interpreters of a plugin, the interpreters loaded
+	// by concurrent executions over it are discarded when the executions finish.
+	goPluginPoolMaxIdle = 4
+)
+
+// goPluginCache has the loaded plugins indexed by the hash of their source code, function and sandbox,
+// so the same plugin is not interpreted again on every processor creation.
+//
+// Every distinct plugin source code (e.g plugins generated with templates) is a different plugin, so the
+// cache is limited and the plugins that are not used are evicted. The processors that already have an
+// evicted plugin can still use it.
+var goPluginCache = &goPluginLRU{
+	max:   goPluginCacheMaxPlugins,
+	lru:   list.New(),
+	pools: map[string]*list.Element{},
+}
+
+// goPluginLRU is a least recently used cache of plugin pools.
+type goPluginLRU struct {
+	mu    sync.Mutex
+	max   int
+	lru   *list.List // The most recently used first.
+	pools map[string]*list.Element
+}
+
+type goPluginLRUEntry struct {
+	key  string
+	pool any
+}
+
+func (c *goPluginLRU) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.pools[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+
+	return e.Value.(*goPluginLRUEntry).pool, true
+}
+
+// add stores the pool on the cache evicting the least recently used pools if the cache is full, if the
+// key is already on the cache, the cached pool is returned instead.
+func (c *goPluginLRU) add(key string, pool any) any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.pools[key]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*goPluginLRUEntry).pool
+	}
+
+	c.pools[key] = c.lru.PushFront(&goPluginLRUEntry{key: key, pool: pool})
+	for c.lru.Len() > c.max {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.pools, oldest.Value.(*goPluginLRUEntry).key)
+	}
+
+	return pool
+}
+
+// goPlugin is a plugin function loaded on its own interpreter.
+type goPlugin[T any] struct {
+	interp *interp.Interpreter
+	fn     T
+	stdout *goPluginOutput
+	stderr *goPluginOutput
+}
+
+// goPluginPool has the interpreters loaded with the same plugin.
+//
+// An interpreter is only used by one execution at a time, this way concurrent executions don't share
+// the plugin state and stopping an execution doesn't stop the other ones. New interpreters are loaded
+// when all of them are being used, and only a few of them are kept idle.
+//
+// The interpreters are not reset between executions, the plugin package level variables keep the values
+// of the previous executions of the interpreter (even from other processors), so the plugins that keep
+// state on them have results that depend on the execution order. Yaegi can't reset an interpreter, and
+// loading a new one on every execution is what the cache avoids, so it's documented on the plugins.
+type goPluginPool[T any] struct {
+	load func(ctx context.Context) (goPlugin[T], error)
+	mu   sync.Mutex
+	free []goPlugin[T]
+}
+
+// getGoPluginPool returns the pool of the plugin from the cache, if missing, it will load the plugin
+// and store the pool on the cache.
+func getGoPluginPool[T any](ctx context.Context, src string, sandbox GoPluginSandbox, funcName string) (*goPluginPool[T], error) {
+	key := goPluginCacheKey(src, sandbox, funcName)
+
+	if cached, ok := goPluginCache.get(key); ok {
+		if pool, ok := cached.(*goPluginPool[T]); ok {
+			return pool, nil
+		}
+	}
+
+	pool := &goPluginPool[T]{
+		load: func(ctx context.Context) (goPlugin[T], error) {
+			stdout, stderr := &goPluginOutput{}, &goPluginOutput{}
+			yaegiInterp, pluginFuncTmp, err := loadRawPlugin(ctx, src, sandbox, funcName, stdout, stderr)
+			if err != nil {
+				return goPlugin[T]{}, err
+			}
+
+			pluginFunc, ok := pluginFuncTmp.Interface().(T)
+			if !ok {
+				return goPlugin[T]{}, fmt.Errorf("invalid plugin type")
+			}
+
+			return goPlugin[T]{interp: yaegiInterp, fn: pluginFunc, stdout: stdout, stderr: stderr}, nil
+		},
+	}
+
+	// Load the first plugin to fail fast, invalid plugins are not cached.
+	plugin, err := pool.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pool.put(plugin)
+
+	// Other loads of the same plugin could have won the race.
+	if cached, ok := goPluginCache.add(key, pool).(*goPluginPool[T]); ok {
+		return cached, nil
+	}
+
+	return pool, nil
+}
+
+// run executes the plugin function on a free interpreter. Plugins could ignore the context, so we stop the
+// interpreter ourselves when the context is done, stopped interpreters are not reused. The plugin output is
+// written on the context output writers only during the execution.
+func (g *goPluginPool[T]) run(ctx context.Context, f func(fn T) error) error {
+	plugin, err := g.get(ctx)
+	if err != nil {
+		return fmt.Errorf("could not load plugin: %w", err)
+	}
+
+	stdout, stderr := goPluginOutputFromContext(ctx)
+	plugin.stdout.set(stdout)
+	plugin.stderr.set(stderr)
+
+	stopped, release := stopInterpreterOnDone(ctx, plugin.interp)
+	err = f(plugin.fn)
+	release()
+
+	plugin.stdout.set(nil)
+	plugin.stderr.set(nil)
+
+	if stopped() {
+		return fmt.Errorf("plugin execution stopped: %w", ctx.Err())
+	}
+
+	g.put(plugin)
+
+	return err
+}
+
+func (g *goPluginPool[T]) get(ctx context.Context) (goPlugin[T], error) {
+	g.mu.Lock()
+	if len(g.free) > 0 {
+		plugin := g.free[len(g.free)-1]
+		g.free = g.free[:len(g.free)-1]
+		g.mu.Unlock()
+		return plugin, nil
+	}
+	g.mu.Unlock()
+
+	return g.load(ctx)
+}
+
+func (g *goPluginPool[T]) put(plugin goPlugin[T]) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.free) >= goPluginPoolMaxIdle {
+		return
+	}
+	g.free = append(g.free, plugin)
+}
+
+func goPluginCacheKey(src string, sandbox GoPluginSandbox, funcName string) string {
+	h := sha256.New()
+	for _, s := range []string{funcName, string(sandbox.preset()), strings.Join(sandbox.Allow, ","), src} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,218 @@
+package process_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+)
+
+func TestGoPluginProcessorCacheReusesLoadedPlugins(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// The state of the plugin tells us if the same interpreter has been reused.
+	plugin := `
+package testplugin
+
+import (
+	"context"
+	"strconv"
+)
+
+var calls = 0
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	calls++
+	return inputData + strconv.Itoa(calls), nil
+}
+`
+
+	for i := 1; i <= 3; i++ {
+		p, err := process.NewGoPluginV1Processor(context.TODO(), plugin, nil, process.GoPluginSandbox{})
+		require.NoError(err)
+
+		gotResult, err := p.Process(context.TODO(), "cache-reuse-")
+		require.NoError(err)
+		assert.Equal(fmt.Sprintf("cache-reuse-%d", i), gotResult)
+	}
+
+	// A different sandbox should not reuse the plugin.
+	p, err := process.NewGoPluginV1Processor(context.TODO(), plugin, nil, process.GoPluginSandbox{Preset: process.SandboxPresetSafe})
+	require.NoError(err)
+
+	gotResult, err := p.Process(context.TODO(), "cache-reuse-")
+	require.NoError(err)
+	assert.Equal("cache-reuse-1", gotResult)
+}
+
+func TestGoPluginProcessorCacheConcurrentExecutions(t *testing.T) {
+	require := require.New(t)
+
+	plugin := `
+package testplugin
+
+import (
+	"context"
+	"strings"
+)
+
+var last = ""
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	last = inputData
+	for i := 0; i < 100; i++ {
+		last = strings.ToUpper(last)
+	}
+	return last + vars["suffix"], nil
+}
+`
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			p, err := process.NewGoPluginV1Processor(context.TODO(), plugin, map[string]string{"suffix": "!"}, process.GoPluginSandbox{})
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			input := fmt.Sprintf("concurrent-%d", i)
+			gotResult, err := p.Process(context.TODO(), input)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			if exp := fmt.Sprintf("CONCURRENT-%d!", i); gotResult != exp {
+				errs <- fmt.Errorf("expected %q, got %q", exp, gotResult)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(err)
+	}
+}
+
+func TestGoPluginProcessorCacheStoppedExecution(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	plugin := `
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	if inputData == "loop" {
+		for {
+		}
+	}
+	return inputData, nil
+}
+`
+
+	p, err := process.NewGoPluginV1Processor(context.TODO(), plugin, nil, process.GoPluginSandbox{})
+	require.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = p.Process(ctx, "loop")
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	// The cached plugin should still work after a stopped execution.
+	p, err = process.NewGoPluginV1Processor(context.TODO(), plugin, nil, process.GoPluginSandbox{})
+	require.NoError(err)
+
+	gotResult, err := p.Process(context.TODO(), "this is a test")
+	require.NoError(err)
+	assert.Equal("this is a test", gotResult)
+}
+
+func TestGoPluginProcessorCacheEvictsUnusedPlugins(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	plugin := `
+package testplugin
+
+import (
+	"context"
+	"strconv"
+)
+
+var evictCalls = 0
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	evictCalls++
+	return inputData + strconv.Itoa(evictCalls), nil
+}
+`
+	processPlugin := func() string {
+		p, err := process.NewGoPluginV1Processor(context.TODO(), plugin, nil, process.GoPluginSandbox{})
+		require.NoError(err)
+
+		gotResult, err := p.Process(context.TODO(), "cache-evict-")
+		require.NoError(err)
+		return gotResult
+	}
+
+	assert.Equal("cache-evict-1", processPlugin())
+	assert.Equal("cache-evict-2", processPlugin())
+
+	// Fill the cache with other plugins so the plugin is evicted.
+	for i := 0; i < 100; i++ {
+		other := fmt.Sprintf("%s\n// Plugin %d.\n", plugin, i)
+		_, err := process.NewGoPluginV1Processor(context.TODO(), other, nil, process.GoPluginSandbox{})
+		require.NoError(err)
+	}
+
+	// A new interpreter should be loaded for the evicted plugin.
+	assert.Equal("cache-evict-1", processPlugin())
+}
+
+func TestGoPluginProcessorCacheKeepsPluginState(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// Loaded plugins are not reset between executions, the package level variables keep the values of the
+	// previous executions, even from other processors with the same plugin.
+	plugin := `
+package testplugin
+
+import "context"
+
+var previousInput = ""
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	previous := previousInput
+	previousInput = inputData
+	return previous, nil
+}
+`
+
+	p1, err := process.NewGoPluginV1Processor(context.TODO(), plugin, nil, process.GoPluginSandbox{})
+	require.NoError(err)
+	gotResult, err := p1.Process(context.TODO(), "first")
+	require.NoError(err)
+	assert.Equal("", gotResult)
+
+	p2, err := process.NewGoPluginV1Processor(context.TODO(), plugin, map[string]string{"other": "vars"}, process.GoPluginSandbox{})
+	require.NoError(err)
+	gotResult, err = p2.Process(context.TODO(), "second")
+	require.NoError(err)
+	assert.Equal("first", gotResult)
+}
@@ -0,0 +1,56 @@
+package process
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+type goPluginOutputKey struct{}
+
+type goPluginOutputWriters struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// ContextWithGoPluginOutput returns a context that makes the Go plugins executed with it write their standard
+// output and error (e.g `fmt.Println`, `log.Println` or `os.Stderr`) on stdout and stderr. Without it, the
+// plugins output is discarded, the provider process output is not for the plugins.
+func ContextWithGoPluginOutput(ctx context.Context, stdout, stderr io.Writer) context.Context {
+	return context.WithValue(ctx, goPluginOutputKey{}, goPluginOutputWriters{stdout: stdout, stderr: stderr})
+}
+
+func goPluginOutputFromContext(ctx context.Context) (stdout, stderr io.Writer) {
+	w, ok := ctx.Value(goPluginOutputKey{}).(goPluginOutputWriters)
+	if !ok {
+		return nil, nil
+	}
+
+	return w.stdout, w.stderr
+}
+
+// goPluginOutput is a standard output (or error) of a plugin interpreter. The interpreters are reused by
+// many executions (one at a time), so it writes on the writer of the running execution. The writes are
+// serialized, so the plugins can write from multiple goroutines.
+type goPluginOutput struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (g *goPluginOutput) Write(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Without execution (e.g the plugin goroutines that outlive the execution), it's discarded.
+	if g.w == nil {
+		return len(p), nil
+	}
+
+	return g.w.Write(p)
+}
+
+func (g *goPluginOutput) set(w io.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.w = w
+}
@@ -0,0 +1,178 @@
+package process_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+)
+
+func TestGoPluginOutput(t *testing.T) {
+	tests := map[string]struct {
+		plugin    string
+		sandbox   process.GoPluginSandbox
+		inputData string
+		expStdout string
+		expStderr string
+		expErr    bool
+	}{
+		"A plugin without output should not have output.": {
+			plugin: `
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return inputData, nil
+}
+`,
+			inputData: "test",
+		},
+
+		"The plugin standard output and error should be captured.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	fmt.Println("input:", inputData)
+	fmt.Printf("size: %d\n", len(inputData))
+	fmt.Fprintln(os.Stdout, "stdout")
+	fmt.Fprintln(os.Stderr, "stderr")
+	return inputData, nil
+}
+`,
+			inputData: "test",
+			expStdout: "input: test\nsize: 4\nstdout\n",
+			expStderr: "stderr\n",
+		},
+
+		"The plugin standard input should be empty.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	var s string
+	_, err := fmt.Scanln(&s)
+	fmt.Println("scan:", err)
+
+	data, err := io.ReadAll(os.Stdin)
+	fmt.Println("stdin:", len(data), err)
+	return inputData, nil
+}
+`,
+			inputData: "test",
+			expStdout: "scan: EOF\nstdin: 0 <nil>\n",
+		},
+
+		"The plugin output should be captured with the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"fmt"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	fmt.Print("input: ", inputData)
+	return inputData, nil
+}
+`,
+			sandbox:   process.GoPluginSandbox{Preset: process.SandboxPresetSafe},
+			inputData: "test",
+			expStdout: "input: test",
+		},
+
+		"The plugin output should be captured even if the plugin fails.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"fmt"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	fmt.Println("before failing")
+	return "", fmt.Errorf("something failed")
+}
+`,
+			inputData: "test",
+			expStdout: "before failing\n",
+			expErr:    true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			p, err := process.NewGoPluginV1Processor(context.TODO(), test.plugin, nil, test.sandbox)
+			require.NoError(err)
+
+			var stdout, stderr bytes.Buffer
+			ctx := process.ContextWithGoPluginOutput(context.TODO(), &stdout, &stderr)
+			_, err = p.Process(ctx, test.inputData)
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+			assert.Equal(test.expStdout, stdout.String())
+			assert.Equal(test.expStderr, stderr.String())
+		})
+	}
+}
+
+func TestGoPluginOutputIsIndependentOnEveryExecution(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	plugin := `
+package testplugin
+
+import (
+	"context"
+	"fmt"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	fmt.Print(inputData)
+	return inputData, nil
+}
+`
+	p, err := process.NewGoPluginV1Processor(context.TODO(), plugin, nil, process.GoPluginSandbox{})
+	require.NoError(err)
+
+	// Without output on the context, it's discarded.
+	_, err = p.Process(context.TODO(), "discarded")
+	require.NoError(err)
+
+	// The cached interpreter is reused, but every execution has its own output.
+	var out1, out2 bytes.Buffer
+	_, err = p.Process(process.ContextWithGoPluginOutput(context.TODO(), &out1, &out1), "first")
+	require.NoError(err)
+	_, err = p.Process(process.ContextWithGoPluginOutput(context.TODO(), &out2, &out2), "second")
+	require.NoError(err)
+
+	assert.Equal("first", out1.String())
+	assert.Equal("second", out2.String())
+}
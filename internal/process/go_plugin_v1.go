@@ -3,15 +3,11 @@ package process
 import (
 	"context"
 	"fmt"
-	"regexp"
-
-	"github.com/traefik/yaegi/interp"
-	"github.com/traefik/yaegi/stdlib"
 )
 
-func NewGoPluginV1Processor(ctx context.Context, pluginData string, vars map[string]string) (Processor, error) {
+func NewGoPluginV1Processor(ctx context.Context, pluginData string, vars map[string]string, sandbox GoPluginSandbox) (Processor, error) {
 	// Create Yaegi plugin.
-	plugin, err := loadRawProcessorPluginV1(ctx, pluginData)
+	plugin, err := loadRawProcessorPluginV1(ctx, pluginData, sandbox)
 	if err != nil {
 		return nil, fmt.Errorf("could not load plugin: %w", err)
 	}
@@ -26,48 +22,22 @@ func NewGoPluginV1Processor(ctx context.Context, pluginData string, vars map[str
 //nolint:revive
 type ProcessorPluginV1 = func(ctx context.Context, inputData string, vars map[string]string) (result string, err error)
 
-var packageRegexp = regexp.MustCompile(`(?m)^package +([^\s]+) *$`)
-
-func loadRawProcessorPluginV1(ctx context.Context, src string) (ProcessorPluginV1, error) {
-	// Load the plugin in a new interpreter.
-	// For each plugin we need to use an independent interpreter to avoid name collisions.
-	yaegiInterp, err := newYaeginInterpreter()
-	if err != nil {
-		return nil, fmt.Errorf("could not create a new Yaegi interpreter: %w", err)
-	}
-
-	_, err = yaegiInterp.EvalWithContext(ctx, src)
-	if err != nil {
-		return nil, fmt.Errorf("could not evaluate plugin source code: %w", err)
-	}
-
-	// Discover package name.
-	packageMatch := packageRegexp.FindStringSubmatch(src)
-	if len(packageMatch) != 2 {
-		return nil, fmt.Errorf("invalid plugin source code, could not get package name")
-	}
-	packageName := packageMatch[1]
-
-	// Get plugin logic.
-	pluginFuncTmp, err := yaegiInterp.EvalWithContext(ctx, fmt.Sprintf("%s.ProcessorPluginV1", packageName))
-	if err != nil {
-		return nil, fmt.Errorf("could not get plugin: %w", err)
-	}
-
-	pluginFunc, ok := pluginFuncTmp.Interface().(ProcessorPluginV1)
-	if !ok {
-		return nil, fmt.Errorf("invalid plugin type")
-	}
-
-	return pluginFunc, nil
-}
-
-func newYaeginInterpreter() (*interp.Interpreter, error) {
-	i := interp.New(interp.Options{})
-	err := i.Use(stdlib.Symbols)
+func loadRawProcessorPluginV1(ctx context.Context, src string, sandbox GoPluginSandbox) (ProcessorPluginV1, error) {
+	pool, err := getGoPluginPool[ProcessorPluginV1](ctx, src, sandbox, "ProcessorPluginV1")
 	if err != nil {
-		return nil, fmt.Errorf("could not use stdlib symbols: %w", err)
+		return nil, err
 	}
 
-	return i, nil
+	return func(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+		var result string
+		err := pool.run(ctx, func(pluginFunc ProcessorPluginV1) (err error) {
+			result, err = pluginFunc(ctx, inputData, vars)
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+
+		return result, nil
+	}, nil
 }
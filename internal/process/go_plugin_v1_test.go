@@ -3,6 +3,7 @@ package process_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -12,11 +13,13 @@ import (
 
 func TestGoPluginV1ProcessorProcess(t *testing.T) {
 	tests := map[string]struct {
-		plugin    string
-		inputData string
-		vars      map[string]string
-		expResult string
-		expErr    bool
+		plugin     string
+		sandbox    process.GoPluginSandbox
+		inputData  string
+		vars       map[string]string
+		expResult  string
+		expLoadErr bool
+		expErr     bool
 	}{
 		"Simple noop plugin should return the same data.": {
 			plugin: `
@@ -73,6 +76,280 @@ func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]st
 			vars:      map[string]string{"a": "b", "x": "y"},
 			expResult: "this is a testa=b,x=y",
 		},
+
+		"A plugin using network packages should be allowed without sandbox restrictions.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"net/http"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return http.MethodGet, nil
+}
+`,
+			expResult: "GET",
+		},
+
+		"A plugin using network packages should fail on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"net/http"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return http.MethodGet, nil
+}
+`,
+			sandbox:    process.GoPluginSandbox{Preset: process.SandboxPresetSafe},
+			expLoadErr: true,
+		},
+
+		"A plugin using network packages allowed explicitly should be allowed on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"net/http"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return http.MethodGet, nil
+}
+`,
+			sandbox:   process.GoPluginSandbox{Preset: process.SandboxPresetSafe, Allow: []string{"net/http"}},
+			expResult: "GET",
+		},
+
+		"A plugin reading the environment from the proc filesystem should fail on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"os"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	env, err := os.ReadFile("/proc/self/environ")
+	return string(env), err
+}
+`,
+			sandbox:    process.GoPluginSandbox{Preset: process.SandboxPresetSafe},
+			expLoadErr: true,
+		},
+
+		"A plugin reading files with ioutil should fail on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"io/ioutil"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	data, err := ioutil.ReadFile("/etc/hosts")
+	return string(data), err
+}
+`,
+			sandbox:    process.GoPluginSandbox{Preset: process.SandboxPresetSafe},
+			expLoadErr: true,
+		},
+
+		"A plugin checking files should fail on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"os"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	_, err := os.Stat("/etc/hosts")
+	return "", err
+}
+`,
+			sandbox:    process.GoPluginSandbox{Preset: process.SandboxPresetSafe},
+			expLoadErr: true,
+		},
+
+		"A plugin listing files should fail on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"path/filepath"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	files, err := filepath.Glob("/etc/*")
+	return files[0], err
+}
+`,
+			sandbox:    process.GoPluginSandbox{Preset: process.SandboxPresetSafe},
+			expLoadErr: true,
+		},
+
+		"A plugin reading files with templates should fail on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"text/template"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	_, err := template.ParseFiles("/etc/hosts")
+	return "", err
+}
+`,
+			sandbox:    process.GoPluginSandbox{Preset: process.SandboxPresetSafe},
+			expLoadErr: true,
+		},
+
+		"A plugin reading files allowed explicitly should be allowed on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"os"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	_, err := os.Stat("/this/file/does/not/exist")
+	if os.IsNotExist(err) {
+		return "missing", nil
+	}
+	return "", err
+}
+`,
+			sandbox:   process.GoPluginSandbox{Preset: process.SandboxPresetSafe, Allow: []string{"os.Stat"}},
+			expResult: "missing",
+		},
+
+		"A package with denied symbols allowed explicitly should be allowed on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"path/filepath"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	_, err := filepath.Glob("[")
+	return err.Error(), nil
+}
+`,
+			sandbox:   process.GoPluginSandbox{Preset: process.SandboxPresetSafe, Allow: []string{"path/filepath"}},
+			expResult: "syntax error in pattern",
+		},
+
+		"A plugin writing files should fail on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"os"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return "", os.WriteFile("/tmp/test", []byte(inputData), 0644)
+}
+`,
+			sandbox:    process.GoPluginSandbox{Preset: process.SandboxPresetSafe},
+			expLoadErr: true,
+		},
+
+		"A plugin changing files permissions with a file handle should fail on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"os"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	f, err := os.Open("/tmp/test")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return "", f.Chmod(0777)
+}
+`,
+			sandbox:    process.GoPluginSandbox{Preset: process.SandboxPresetSafe},
+			expLoadErr: true,
+		},
+
+		"A plugin using a file system with file handles should fail on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"os"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	f, err := os.DirFS("/tmp").Open("test")
+	if err != nil {
+		return "", err
+	}
+	return "", f.(interface{ Chmod(os.FileMode) error }).Chmod(0777)
+}
+`,
+			sandbox:    process.GoPluginSandbox{Preset: process.SandboxPresetSafe},
+			expLoadErr: true,
+		},
+
+		"A plugin writing files allowed explicitly should be allowed on the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"os"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	f := os.WriteFile
+	if f == nil {
+		return "", nil
+	}
+	return "allowed", nil
+}
+`,
+			sandbox:   process.GoPluginSandbox{Preset: process.SandboxPresetSafe, Allow: []string{"os.WriteFile"}},
+			expResult: "allowed",
+		},
+
+		"An unknown package on the sandbox allow list should fail.": {
+			plugin: `
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return inputData, nil
+}
+`,
+			sandbox:    process.GoPluginSandbox{Preset: process.SandboxPresetSafe, Allow: []string{"github.com/slok/unknown"}},
+			expLoadErr: true,
+		},
 	}
 
 	for name, test := range tests {
@@ -80,7 +357,11 @@ func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]st
 			assert := assert.New(t)
 			require := require.New(t)
 
-			plugin, err := process.NewGoPluginV1Processor(context.TODO(), test.plugin, test.vars)
+			plugin, err := process.NewGoPluginV1Processor(context.TODO(), test.plugin, test.vars, test.sandbox)
+			if test.expLoadErr {
+				assert.Error(err)
+				return
+			}
 			require.NoError(err)
 
 			gotRes, err := plugin.Process(context.TODO(), test.inputData)
@@ -93,3 +374,53 @@ func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]st
 		})
 	}
 }
+
+func TestGoPluginV1ProcessorProcessCancel(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// A plugin that ignores the context.
+	plugin := `
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	for {
+	}
+}
+`
+	p, err := process.NewGoPluginV1Processor(context.TODO(), plugin, nil, process.GoPluginSandbox{})
+	require.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+
+	// The plugin should be stopped, otherwise this would block forever.
+	_, err = p.Process(ctx, "")
+	assert.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func TestGoPluginV1ProcessorSandboxError(t *testing.T) {
+	assert := assert.New(t)
+
+	plugin := `
+package testplugin
+
+import (
+	"context"
+	"net/http"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return http.MethodGet, nil
+}
+`
+	_, err := process.NewGoPluginV1Processor(context.TODO(), plugin, nil, process.GoPluginSandbox{Preset: process.SandboxPresetSafe})
+
+	// The error should tell the forbidden package.
+	var sandboxErr process.SandboxError
+	if assert.ErrorAs(err, &sandboxErr) {
+		assert.Equal("net/http", sandboxErr.Package)
+	}
+}
@@ -0,0 +1,93 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// NewGoPluginV2Processor returns a processor that decodes the JSON input data, passes the decoded value and
+// the typed vars to the plugin and encodes the plugin result as JSON.
+func NewGoPluginV2Processor(ctx context.Context, pluginData string, vars map[string]any, sandbox GoPluginSandbox) (Processor, error) {
+	// Create Yaegi plugin.
+	plugin, err := loadRawProcessorPluginV2(ctx, pluginData, sandbox)
+	if err != nil {
+		return nil, fmt.Errorf("could not load plugin: %w", err)
+	}
+
+	return ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+		var input any
+		err := json.Unmarshal([]byte(inputData), &input)
+		if err != nil {
+			return "", fmt.Errorf("could not decode input data into JSON: %w", err)
+		}
+
+		result, err := plugin(ctx, input, vars)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := marshalJSON(result, false)
+		if err != nil {
+			return "", fmt.Errorf("could not encode plugin result into JSON: %w", err)
+		}
+
+		return string(data), nil
+	}), nil
+}
+
+// NewGoPluginV2ValueProcessor is like NewGoPluginV2Processor but the input is an already decoded value that
+// is passed to the plugin as it is. The result is encoded as JSON and decoded again, so the result value uses
+// the same data model as decoded JSON.
+func NewGoPluginV2ValueProcessor(ctx context.Context, pluginData string, vars map[string]any, sandbox GoPluginSandbox) (ValueProcessor, error) {
+	// Create Yaegi plugin.
+	plugin, err := loadRawProcessorPluginV2(ctx, pluginData, sandbox)
+	if err != nil {
+		return nil, fmt.Errorf("could not load plugin: %w", err)
+	}
+
+	return ValueProcessorFunc(func(ctx context.Context, input any) (Results, error) {
+		result, err := plugin(ctx, input, vars)
+		if err != nil {
+			return Results{}, err
+		}
+
+		data, err := marshalJSON(result, false)
+		if err != nil {
+			return Results{}, fmt.Errorf("could not encode plugin result into JSON: %w", err)
+		}
+
+		var value any
+		err = json.Unmarshal(data, &value)
+		if err != nil {
+			return Results{}, fmt.Errorf("could not decode plugin result JSON: %w", err)
+		}
+
+		return Results{Result: string(data), Results: []string{string(data)}, Values: []any{value}}, nil
+	}), nil
+}
+
+// ProcessorPluginV2 knows how to process decoded input data with custom logic and return a result that will be encoded.
+//
+//nolint:revive
+type ProcessorPluginV2 = func(ctx context.Context, input any, vars map[string]any) (result any, err error)
+
+func loadRawProcessorPluginV2(ctx context.Context, src string, sandbox GoPluginSandbox) (ProcessorPluginV2, error) {
+	pool, err := getGoPluginPool[ProcessorPluginV2](ctx, src, sandbox, "ProcessorPluginV2")
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, input any, vars map[string]any) (any, error) {
+		var result any
+		err := pool.run(ctx, func(pluginFunc ProcessorPluginV2) (err error) {
+			result, err = pluginFunc(ctx, input, vars)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	}, nil
+}
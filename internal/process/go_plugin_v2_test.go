@@ -0,0 +1,195 @@
+package process_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+)
+
+func TestGoPluginV2ProcessorProcess(t *testing.T) {
+	tests := map[string]struct {
+		plugin     string
+		inputData  string
+		vars       map[string]any
+		expResult  string
+		expLoadErr bool
+		expErr     bool
+	}{
+		"Simple noop plugin should return the same data.": {
+			plugin: `
+package testplugin
+
+import "context"
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	return input, nil
+}
+`,
+			inputData: `{"a": "b", "c": [1, 2, 3]}`,
+			expResult: `{"a":"b","c":[1,2,3]}`,
+		},
+
+		"An error on the plugin should fail.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"fmt"
+)
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	return nil, fmt.Errorf("error from plugin")
+}
+`,
+			inputData: `{}`,
+			expErr:    true,
+		},
+
+		"An invalid JSON input should fail.": {
+			plugin: `
+package testplugin
+
+import "context"
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	return input, nil
+}
+`,
+			inputData: `{"a" b"}`,
+			expErr:    true,
+		},
+
+		"Decoded input and typed variables should be accessible from the plugin.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"fmt"
+)
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	users, ok := input.([]any)
+	if !ok {
+		return nil, fmt.Errorf("input is not a list")
+	}
+
+	limit := int(vars["limit"].(float64))
+	prefix := vars["prefix"].(string)
+
+	result := []string{}
+	for _, u := range users[:limit] {
+		result = append(result, prefix + u.(map[string]any)["name"].(string))
+	}
+
+	return map[string]any{"users": result, "enabled": vars["enabled"]}, nil
+}
+`,
+			inputData: `[{"name": "user1"}, {"name": "user2"}, {"name": "user3"}]`,
+			vars:      map[string]any{"limit": float64(2), "prefix": "x-", "enabled": true},
+			expResult: `{"enabled":true,"users":["x-user1","x-user2"]}`,
+		},
+
+		"A plugin with an invalid signature should fail.": {
+			plugin: `
+package testplugin
+
+import "context"
+
+func ProcessorPluginV2(ctx context.Context, input string, vars map[string]string) (string, error) {
+	return input, nil
+}
+`,
+			inputData:  `{}`,
+			expLoadErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			plugin, err := process.NewGoPluginV2Processor(context.TODO(), test.plugin, test.vars, process.GoPluginSandbox{})
+			if test.expLoadErr {
+				assert.Error(err)
+				return
+			}
+			require.NoError(err)
+
+			gotRes, err := plugin.Process(context.TODO(), test.inputData)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expResult, gotRes)
+			}
+		})
+	}
+}
+
+func TestGoPluginV2ProcessorProcessValue(t *testing.T) {
+	tests := map[string]struct {
+		plugin     string
+		input      any
+		expResults process.Results
+		expErr     bool
+	}{
+		"The input value should be passed to the plugin as it is.": {
+			plugin: `
+package testplugin
+
+import "context"
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	m := input.(map[string]any)
+	return map[string]any{"count": m["count"].(int) + 1, "tags": m["tags"]}, nil
+}
+`,
+			input: map[string]any{"count": 1, "tags": []any{"a", "b"}},
+			expResults: process.Results{
+				Result:  `{"count":2,"tags":["a","b"]}`,
+				Results: []string{`{"count":2,"tags":["a","b"]}`},
+				Values:  []any{map[string]any{"count": 2.0, "tags": []any{"a", "b"}}},
+			},
+		},
+
+		"An error on the plugin should fail.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"fmt"
+)
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	return nil, fmt.Errorf("error from plugin")
+}
+`,
+			input:  "test",
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			plugin, err := process.NewGoPluginV2ValueProcessor(context.TODO(), test.plugin, nil, process.GoPluginSandbox{})
+			require.NoError(err)
+
+			gotRes, err := plugin.ProcessValue(context.TODO(), test.input)
+			if test.expErr {
+				assert.Error(err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expResults, gotRes)
+			}
+		})
+	}
+}
@@ -1,19 +1,125 @@
 package process
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 
 	"github.com/itchyny/gojq"
 )
 
-func NewJQProcessor(ctx context.Context, jqExpression string, metadata map[string]string, prettyResult bool) (Processor, error) {
+// JQOptions are the options of the JQ processor, they mirror jq CLI flags.
+type JQOptions struct {
+	// Pretty renders the JSON results indented with tabs (like jq `--tab`).
+	Pretty bool
+	// Indent renders the JSON results indented with the number of spaces (like jq `--indent n`), up to 7.
+	Indent int
+	// RawOutput renders the string results without quotes (like jq `--raw-output`).
+	RawOutput bool
+	// JoinOutput is the same as RawOutput, but the results are not separated by newlines (like jq `--join-output`).
+	JoinOutput bool
+	// ASCIIOutput escapes the non ASCII characters (like jq `--ascii-output`).
+	ASCIIOutput bool
+
+	// Slurp reads all the inputs into an array and runs the expression once (like jq `--slurp`), with
+	// RawInput, all the input data will be a single string.
+	Slurp bool
+	// RawInput reads each line of the input data as a string instead of JSON (like jq `--raw-input`).
+	RawInput bool
+	// NullInput runs the expression once with `null` as input (like jq `--null-input`), the input data
+	// can be read with `input` and `inputs`.
+	NullInput bool
+
+	// Modules are the JQ modules source code by name, they can be used in the expression with
+	// `import` and `include` (e.g `import "lib" as lib;`).
+	Modules map[string]string
+	// LibraryPaths are the directories where the modules that are not in Modules will be searched
+	// (like jq `-L`).
+	LibraryPaths []string
+}
+
+// NewJQProcessor returns a processor that executes the JQ expression, the vars are bound as JQ variables
+// (e.g `$name`) with their type, so they can be strings or any decoded JSON value (like jq `--argjson`).
+func NewJQProcessor(ctx context.Context, jqExpression string, metadata map[string]any, opts JQOptions) (MultiResultProcessor, error) {
+	run, err := newJQRunner(jqExpression, metadata, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return MultiResultProcessorFunc(func(ctx context.Context, inputData string) (Results, error) {
+		return run(ctx, newJQInputIter(inputData, opts))
+	}), nil
+}
+
+// NewJQValueProcessor is like NewJQProcessor but the input is an already decoded value, that is used as the
+// single input of the expression. Raw input is not supported, the input is not text.
+func NewJQValueProcessor(ctx context.Context, jqExpression string, metadata map[string]any, opts JQOptions) (ValueProcessor, error) {
+	if opts.RawInput {
+		return nil, fmt.Errorf("raw input can't be used with decoded input values")
+	}
+
+	run, err := newJQRunner(jqExpression, metadata, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ValueProcessorFunc(func(ctx context.Context, input any) (Results, error) {
+		return run(ctx, newJQValueIter(input, opts))
+	}), nil
+}
+
+// jqSourceError returns a SourceError of src if err is a JQ parse error, they have the offset of the
+// token that failed. The source is the name of src when it's not the main expression (e.g a module name).
+func jqSourceError(source, src string, err error) error {
+	var parseErr interface{ Token() (string, int) }
+	if !errors.As(err, &parseErr) {
+		return err
+	}
+
+	// The offset is the end of the token.
+	token, offset := parseErr.Token()
+	srcErr := newSourceErrorFromOffset(src, offset-len(token), err.Error())
+	srcErr.Source = source
+	return srcErr
+}
+
+// jqRunner executes the JQ expression on the inputs.
+type jqRunner func(ctx context.Context, inputs gojq.Iter) (Results, error)
+
+// JQLibraryModules returns the modules (e.g `import "lib" as lib;`) and data (e.g `import "data" as $data;`)
+// loaded from the library paths by the JQ expression, by name (data names have the `.json` suffix). The
+// result of the expression depends on them, although they are not part of the expression.
+func JQLibraryModules(jqExpression string, metadata map[string]any, opts JQOptions) (map[string]string, error) {
+	moduleLoader := newJQModuleLoader(opts.Modules, opts.LibraryPaths)
+	_, err := newJQRunnerWithModuleLoader(jqExpression, metadata, opts, moduleLoader)
+	if err != nil {
+		return nil, err
+	}
+
+	return moduleLoader.libraryModules(), nil
+}
+
+func newJQRunner(jqExpression string, metadata map[string]any, opts JQOptions) (jqRunner, error) {
+	return newJQRunnerWithModuleLoader(jqExpression, metadata, opts, newJQModuleLoader(opts.Modules, opts.LibraryPaths))
+}
+
+func newJQRunnerWithModuleLoader(jqExpression string, metadata map[string]any, opts JQOptions, moduleLoader *jqModuleLoader) (jqRunner, error) {
+	if opts.Indent < 0 || opts.Indent > 7 {
+		return nil, fmt.Errorf("invalid indent %d, must be between 0 and 7", opts.Indent)
+	}
+
 	expression, err := gojq.Parse(jqExpression)
 	if err != nil {
-		return nil, fmt.Errorf("could not parse JQ expression: %w", err)
+		return nil, fmt.Errorf("could not parse JQ expression: %w", jqSourceError("", jqExpression, err))
 	}
 
 	// Extract variables.
@@ -33,48 +139,352 @@ func NewJQProcessor(ctx context.Context, jqExpression string, metadata map[strin
 		varVals = append(varVals, metadata[strings.TrimPrefix(k, "$")])
 	}
 
-	jqc, err := gojq.Compile(expression, gojq.WithVariables(varKeys))
+	// Check the modules before using them, so we know which module is wrong.
+	for name, src := range opts.Modules {
+		_, err := gojq.Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse JQ module %q: %w", name, jqSourceError(name, src, err))
+		}
+	}
+
+	// The inputs are bound on compilation, so we compile once to fail fast and then on every execution.
+	compile := func(inputs gojq.Iter) (*gojq.Code, error) {
+		opts := append([]gojq.CompilerOption{
+			gojq.WithVariables(varKeys),
+			gojq.WithInputIter(inputs),
+			gojq.WithModuleLoader(moduleLoader),
+		}, jqFunctionOptions()...)
+		return gojq.Compile(expression, opts...)
+	}
+	_, err = compile(newJQInputIter("", opts))
 	if err != nil {
 		return nil, fmt.Errorf("could not compile JQ expression: %w", err)
 	}
 
-	return ProcessorFunc(func(ctx context.Context, inputData string) (result string, err error) {
-		// Unmarshal into JSON.
-		var d any
-		err = json.Unmarshal([]byte(inputData), &d)
+	return func(ctx context.Context, inputs gojq.Iter) (Results, error) {
+		// The main inputs and `input`, `inputs` builtins consume the same inputs.
+		jqc, err := compile(inputs)
 		if err != nil {
-			return "", fmt.Errorf("could not decode input data into JSON: %w", err)
+			return Results{}, fmt.Errorf("could not compile JQ expression: %w", err)
 		}
 
 		// Execute JQ.
-		jqi := jqc.RunWithContext(ctx, d, varVals...)
 		results := []string{}
-		for {
-			v, ok := jqi.Next()
-			if !ok {
-				break
+		values := []any{}
+		run := func(d any) error {
+			jqi := jqc.RunWithContext(ctx, d, varVals...)
+			for {
+				v, ok := jqi.Next()
+				if !ok {
+					return nil
+				}
+
+				if err, ok := v.(error); ok {
+					return fmt.Errorf("jq execution result error: %w", err)
+				}
+
+				result, err := opts.encode(v)
+				if err != nil {
+					return fmt.Errorf("could not unmarshal JSON result: %w", err)
+				}
+				results = append(results, result)
+				values = append(values, v)
+			}
+		}
+
+		// With null input the expression is only executed once, otherwise once per input.
+		if opts.NullInput {
+			err := run(nil)
+			if err != nil {
+				return Results{}, err
+			}
+		} else {
+			for {
+				v, ok := inputs.Next()
+				if !ok {
+					break
+				}
+
+				if err, ok := v.(error); ok {
+					return Results{}, err
+				}
+
+				err := run(v)
+				if err != nil {
+					return Results{}, err
+				}
+			}
+		}
+
+		sep := "\n"
+		if opts.JoinOutput {
+			sep = ""
+		}
+
+		r := strings.Join(results, sep)
+		return Results{Result: r, Results: results, Values: values}, nil
+	}, nil
+}
+
+// jqInputIter iterates over the inputs of the input data, the inputs are decoded lazily, so
+// the data is only read once by the main inputs and `input`, `inputs` builtins.
+type jqInputIter struct {
+	next func() (any, bool)
+}
+
+func (j *jqInputIter) Next() (any, bool) { return j.next() }
+
+func newJQInputIter(inputData string, opts JQOptions) gojq.Iter {
+	var next func() (any, error)
+	switch {
+	case opts.RawInput && opts.Slurp:
+		done := false
+		next = func() (any, error) {
+			if done {
+				return nil, io.EOF
 			}
+			done = true
+			return inputData, nil
+		}
 
-			if err, ok := v.(error); ok {
-				return "", fmt.Errorf("jq execution result error: %w", err)
+	case opts.RawInput:
+		lines := []string{}
+		if inputData != "" {
+			lines = strings.Split(strings.TrimSuffix(inputData, "\n"), "\n")
+		}
+		next = func() (any, error) {
+			if len(lines) == 0 {
+				return nil, io.EOF
 			}
+			line := lines[0]
+			lines = lines[1:]
+			return line, nil
+		}
 
-			result, err := marshalJSON(v, prettyResult)
+	default:
+		// Multiple JSON values are supported (e.g NDJSON).
+		dec := json.NewDecoder(strings.NewReader(inputData))
+		next = func() (any, error) {
+			var v any
+			err := dec.Decode(&v)
 			if err != nil {
-				return "", fmt.Errorf("could not unmarshal JSON result: %w", err)
+				return nil, err
 			}
-			results = append(results, string(result))
+			return v, nil
 		}
 
-		r := strings.Join(results, "\n")
-		return r, nil
-	}), nil
+		if opts.Slurp {
+			decode := next
+			done := false
+			next = func() (any, error) {
+				if done {
+					return nil, io.EOF
+				}
+				done = true
+
+				values := []any{}
+				for {
+					v, err := decode()
+					if errors.Is(err, io.EOF) {
+						return values, nil
+					}
+					if err != nil {
+						return nil, err
+					}
+					values = append(values, v)
+				}
+			}
+		}
+	}
+
+	finished := false
+	return &jqInputIter{next: func() (any, bool) {
+		if finished {
+			return nil, false
+		}
+
+		v, err := next()
+		if errors.Is(err, io.EOF) {
+			finished = true
+			return nil, false
+		}
+		if err != nil {
+			// After an error we can't continue decoding.
+			finished = true
+			return fmt.Errorf("could not decode input data into JSON: %w", err), true
+		}
+
+		return v, true
+	}}
+}
+
+// newJQValueIter returns the decoded input value as the only input, with slurp the input is wrapped in an array.
+func newJQValueIter(input any, opts JQOptions) gojq.Iter {
+	if opts.Slurp {
+		input = []any{input}
+	}
+
+	done := false
+	return &jqInputIter{next: func() (any, bool) {
+		if done {
+			return nil, false
+		}
+		done = true
+		return input, true
+	}}
+}
+
+// jqModuleLoader loads the JQ modules from the inline modules, if the module is missing
+// it will be searched on the library paths.
+type jqModuleLoader struct {
+	modules map[string]string
+	fs      gojq.ModuleLoader
+
+	// The modules and data loaded from the library paths.
+	mu     sync.Mutex
+	loaded map[string]string
+}
+
+func newJQModuleLoader(modules map[string]string, libraryPaths []string) *jqModuleLoader {
+	// Don't share the paths with the loader, it could modify them.
+	paths := make([]string, len(libraryPaths))
+	copy(paths, libraryPaths)
+
+	return &jqModuleLoader{
+		modules: modules,
+		fs:      gojq.NewModuleLoader(paths),
+		loaded:  map[string]string{},
+	}
+}
+
+func (j *jqModuleLoader) LoadModuleWithMeta(name string, meta map[string]any) (*gojq.Query, error) {
+	// Parsed every time because the compiler can modify the query.
+	if src, ok := j.modules[name]; ok {
+		q, err := gojq.Parse(src)
+		if err != nil {
+			return nil, jqSourceError(name, src, err)
+		}
+		return q, nil
+	}
+
+	q, err := j.fs.(interface {
+		LoadModuleWithMeta(string, map[string]any) (*gojq.Query, error)
+	}).LoadModuleWithMeta(name, meta)
+	if err != nil {
+		// The library path modules parse errors have the module source.
+		var parseErr interface {
+			QueryParseError() (name string, src string, err error)
+		}
+		if errors.As(err, &parseErr) {
+			_, src, err := parseErr.QueryParseError()
+			return nil, jqSourceError(name, src, err)
+		}
+		return nil, err
+	}
+	j.setLoaded(name, q.String())
+
+	return q, nil
+}
+
+func (j *jqModuleLoader) LoadJSONWithMeta(name string, meta map[string]any) (any, error) {
+	v, err := j.fs.(interface {
+		LoadJSONWithMeta(string, map[string]any) (any, error)
+	}).LoadJSONWithMeta(name, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := marshalJSON(v, false)
+	if err != nil {
+		return nil, err
+	}
+	j.setLoaded(name+".json", string(data))
+
+	return v, nil
+}
+
+func (j *jqModuleLoader) setLoaded(name, src string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.loaded[name] = src
+}
+
+// libraryModules returns the modules and data that have been loaded from the library paths.
+func (j *jqModuleLoader) libraryModules() map[string]string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	modules := make(map[string]string, len(j.loaded))
+	for k, v := range j.loaded {
+		modules[k] = v
+	}
+	return modules
+}
+
+// encode encodes a JQ result using the output options.
+func (j JQOptions) encode(v any) (string, error) {
+	s, isString := v.(string)
+	if !isString || (!j.RawOutput && !j.JoinOutput) {
+		indent := ""
+		switch {
+		case j.Indent > 0:
+			indent = strings.Repeat(" ", j.Indent)
+		case j.Pretty:
+			indent = "\t"
+		}
+
+		data, err := encodeJSON(v, indent)
+		if err != nil {
+			return "", err
+		}
+		s = string(data)
+	}
+
+	if j.ASCIIOutput {
+		s = escapeNonASCII(s)
+	}
+
+	return s, nil
 }
 
 func marshalJSON(v any, pretty bool) ([]byte, error) {
 	if pretty {
-		return json.MarshalIndent(v, "", "\t")
+		return encodeJSON(v, "\t")
+	}
+
+	return encodeJSON(v, "")
+}
+
+// encodeJSON encodes the value in JSON without escaping HTML characters (e.g `<`, `>` and `&`), objects keys are sorted.
+func encodeJSON(v any, indent string) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the newline added by the encoder.
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// escapeNonASCII escapes the non ASCII characters using JSON unicode escape sequences.
+func escapeNonASCII(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+			continue
+		}
+
+		if r1, r2 := utf16.EncodeRune(r); r1 != unicode.ReplacementChar {
+			fmt.Fprintf(&b, "\\u%04x\\u%04x", r1, r2)
+			continue
+		}
+		fmt.Fprintf(&b, "\\u%04x", r)
 	}
 
-	return json.Marshal(v)
+	return b.String()
 }
@@ -0,0 +1,316 @@
+package process
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+	// Embed the time zones database, so the time zones don't depend on the system.
+	_ "time/tzdata"
+
+	"github.com/BurntSushi/toml"
+	"github.com/apparentlymart/go-cidr/cidr"
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-version"
+	"github.com/itchyny/gojq"
+	"github.com/itchyny/timefmt-go"
+	"gopkg.in/yaml.v3"
+)
+
+// jqFunction is a custom function available on the JQ expressions.
+type jqFunction struct {
+	name     string
+	minArity int
+	maxArity int
+	fn       func(v any, args []any) any
+}
+
+// jqFunctions are the custom functions that the provider adds to the JQ builtins.
+var jqFunctions = []jqFunction{
+	{name: "sha256", fn: jqSHA256},
+	{name: "yaml_decode", fn: jqYAMLDecode},
+	{name: "yaml_encode", fn: jqYAMLEncode},
+	{name: "toml_decode", fn: jqTOMLDecode},
+	{name: "semver_compare", minArity: 1, maxArity: 1, fn: jqSemverCompare},
+	{name: "cidrsubnet", minArity: 2, maxArity: 2, fn: jqCIDRSubnet},
+	{name: "cidrcontains", minArity: 1, maxArity: 1, fn: jqCIDRContains},
+	{name: "uuid_v5", minArity: 1, maxArity: 1, fn: jqUUIDv5},
+	{name: "strftime_tz", minArity: 2, maxArity: 2, fn: jqStrftimeTZ},
+}
+
+func jqFunctionOptions() []gojq.CompilerOption {
+	opts := make([]gojq.CompilerOption, 0, len(jqFunctions))
+	for _, f := range jqFunctions {
+		opts = append(opts, gojq.WithFunction(f.name, f.minArity, f.maxArity, f.fn))
+	}
+	return opts
+}
+
+// jqSHA256 returns the hex encoded sha256 of a string (e.g `"test" | sha256`).
+func jqSHA256(v any, _ []any) any {
+	s, ok := v.(string)
+	if !ok {
+		return jqTypeError("sha256", "string", v)
+	}
+
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+// jqYAMLDecode decodes a YAML string, only the first document is decoded (e.g `.data | yaml_decode`).
+func jqYAMLDecode(v any, _ []any) any {
+	s, ok := v.(string)
+	if !ok {
+		return jqTypeError("yaml_decode", "string", v)
+	}
+
+	var d any
+	err := yaml.Unmarshal([]byte(s), &d)
+	if err != nil {
+		return fmt.Errorf("yaml_decode: %w", err)
+	}
+
+	return normalizeValue("yaml_decode", d)
+}
+
+// jqYAMLEncode encodes a value in YAML indented with 2 spaces (e.g `{"a": 1} | yaml_encode`).
+func jqYAMLEncode(v any, _ []any) any {
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	err := enc.Encode(v)
+	if err != nil {
+		return fmt.Errorf("yaml_encode: %w", err)
+	}
+
+	return b.String()
+}
+
+// jqTOMLDecode decodes a TOML string, the dates are returned as strings (e.g `.data | toml_decode`).
+func jqTOMLDecode(v any, _ []any) any {
+	s, ok := v.(string)
+	if !ok {
+		return jqTypeError("toml_decode", "string", v)
+	}
+
+	d := map[string]any{}
+	_, err := toml.Decode(s, &d)
+	if err != nil {
+		return fmt.Errorf("toml_decode: %w", err)
+	}
+
+	return normalizeValue("toml_decode", d)
+}
+
+// jqSemverCompare compares two semantic versions, returns `-1`, `0` or `1` if the input version is lower, equal
+// or greater than the argument version (e.g `"1.2.0" | semver_compare("1.10.0")`).
+func jqSemverCompare(v any, args []any) any {
+	v1, err := jqSemver(v)
+	if err != nil {
+		return err
+	}
+
+	v2, err := jqSemver(args[0])
+	if err != nil {
+		return err
+	}
+
+	return v1.Compare(v2)
+}
+
+func jqSemver(v any) (*version.Version, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, jqTypeError("semver_compare", "string", v)
+	}
+
+	ver, err := version.NewSemver(s)
+	if err != nil {
+		return nil, fmt.Errorf("semver_compare: %w", err)
+	}
+
+	return ver, nil
+}
+
+// jqCIDRSubnet calculates a subnet of a network prefix, like terraform `cidrsubnet`
+// (e.g `"10.0.0.0/16" | cidrsubnet(8; 2)`).
+func jqCIDRSubnet(v any, args []any) any {
+	s, ok := v.(string)
+	if !ok {
+		return jqTypeError("cidrsubnet", "string", v)
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return fmt.Errorf("cidrsubnet: %w", err)
+	}
+
+	newBits, ok := jqInt(args[0])
+	if !ok {
+		return jqTypeError("cidrsubnet", "integer", args[0])
+	}
+
+	netNum, ok := jqInt(args[1])
+	if !ok {
+		return jqTypeError("cidrsubnet", "integer", args[1])
+	}
+
+	subnet, err := cidr.Subnet(network, newBits, netNum)
+	if err != nil {
+		return fmt.Errorf("cidrsubnet: %w", err)
+	}
+
+	return subnet.String()
+}
+
+// jqCIDRContains checks if a network prefix contains an IP address or a network prefix, like terraform
+// `cidrcontains` (e.g `"10.0.0.0/16" | cidrcontains("10.0.1.5")`).
+func jqCIDRContains(v any, args []any) any {
+	s, ok := v.(string)
+	if !ok {
+		return jqTypeError("cidrcontains", "string", v)
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return fmt.Errorf("cidrcontains: %w", err)
+	}
+
+	contained, ok := args[0].(string)
+	if !ok {
+		return jqTypeError("cidrcontains", "string", args[0])
+	}
+
+	// A network prefix is contained if its first and last addresses are contained.
+	if strings.Contains(contained, "/") {
+		_, containedNetwork, err := net.ParseCIDR(contained)
+		if err != nil {
+			return fmt.Errorf("cidrcontains: %w", err)
+		}
+
+		first, last := cidr.AddressRange(containedNetwork)
+		return network.Contains(first) && network.Contains(last)
+	}
+
+	ip := net.ParseIP(contained)
+	if ip == nil {
+		return fmt.Errorf("cidrcontains: invalid IP address %q", contained)
+	}
+
+	return network.Contains(ip)
+}
+
+// jqUUIDv5 returns the name based UUID (version 5) of the input name on the argument namespace, the
+// namespace can be `dns`, `url`, `oid`, `x500` or an UUID, like terraform `uuidv5`
+// (e.g `"www.example.com" | uuid_v5("dns")`).
+func jqUUIDv5(v any, args []any) any {
+	name, ok := v.(string)
+	if !ok {
+		return jqTypeError("uuid_v5", "string", v)
+	}
+
+	ns, ok := args[0].(string)
+	if !ok {
+		return jqTypeError("uuid_v5", "string", args[0])
+	}
+
+	var namespace uuid.UUID
+	switch ns {
+	case "dns":
+		namespace = uuid.NameSpaceDNS
+	case "url":
+		namespace = uuid.NameSpaceURL
+	case "oid":
+		namespace = uuid.NameSpaceOID
+	case "x500":
+		namespace = uuid.NameSpaceX500
+	default:
+		var err error
+		namespace, err = uuid.Parse(ns)
+		if err != nil {
+			return fmt.Errorf("uuid_v5: invalid namespace %q: %w", ns, err)
+		}
+	}
+
+	return uuid.NewSHA1(namespace, []byte(name)).String()
+}
+
+// jqStrftimeTZ formats a time in a time zone, like `strftime` but the time zone is an IANA time zone name
+// (e.g `Europe/Madrid`). The time can be a unix timestamp or a RFC 3339 string
+// (e.g `"2022-08-11T10:00:00Z" | strftime_tz("%Y-%m-%d %H:%M %Z"; "Europe/Madrid")`).
+func jqStrftimeTZ(v any, args []any) any {
+	var t time.Time
+	switch tv := v.(type) {
+	case string:
+		var err error
+		t, err = time.Parse(time.RFC3339, tv)
+		if err != nil {
+			return fmt.Errorf("strftime_tz: %w", err)
+		}
+	case int:
+		t = time.Unix(int64(tv), 0)
+	case float64:
+		sec, dec := math.Modf(tv)
+		t = time.Unix(int64(sec), int64(dec*1e9))
+	default:
+		return jqTypeError("strftime_tz", "string or number", v)
+	}
+
+	format, ok := args[0].(string)
+	if !ok {
+		return jqTypeError("strftime_tz", "string", args[0])
+	}
+
+	tz, ok := args[1].(string)
+	if !ok {
+		return jqTypeError("strftime_tz", "string", args[1])
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return fmt.Errorf("strftime_tz: %w", err)
+	}
+
+	return timefmt.Format(t.In(loc), format)
+}
+
+func jqInt(v any) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+		return int(v), true
+	}
+
+	return 0, false
+}
+
+func jqTypeError(name, expected string, v any) error {
+	return fmt.Errorf("%s cannot be applied to %s, expected %s", name, jqTypeName(v), expected)
+}
+
+func jqTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int, float64, *big.Int:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
@@ -2,6 +2,8 @@ package process_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,11 +14,12 @@ import (
 
 func TestJQPorcessorProcess(t *testing.T) {
 	tests := map[string]struct {
-		pretty       bool
+		opts         process.JQOptions
 		jqExpression string
 		inputData    string
-		metadata     map[string]string
+		metadata     map[string]any
 		expResult    string
+		expLoadErr   bool
 		expErr       bool
 	}{
 		"Empty JQ map should return empty result.": {
@@ -34,16 +37,271 @@ func TestJQPorcessorProcess(t *testing.T) {
 		"A JQ expression with variables should be executed correctly.": {
 			jqExpression: `. |= . + {"x": $x, "y": $y}`,
 			inputData:    `{"a": "b"}`,
-			metadata:     map[string]string{"x": "something", "y": "otherthing"},
+			metadata:     map[string]any{"x": "something", "y": "otherthing"},
 			expResult:    `{"a":"b","x":"something","y":"otherthing"}`,
 		},
 
+		"A JQ expression with typed variables should be executed correctly.": {
+			jqExpression: `{"count": ($count + 1), "tags": ($tags | map(ascii_upcase)), "obj": $obj.a, "null": $null}`,
+			inputData:    `{}`,
+			metadata: map[string]any{
+				"count": float64(3),
+				"tags":  []any{"a", "b"},
+				"obj":   map[string]any{"a": true},
+				"null":  nil,
+			},
+			expResult: `{"count":4,"null":null,"obj":true,"tags":["A","B"]}`,
+		},
+
+		"HTML characters should not be escaped.": {
+			jqExpression: `.url`,
+			inputData:    `{"url": "https://example.com/?a=1&b=<2>"}`,
+			expResult:    `"https://example.com/?a=1&b=<2>"`,
+		},
+
+		"Raw output should render strings without quotes.": {
+			jqExpression: `.a[]`,
+			inputData:    `{"a": ["b", 1, {"c": "d"}]}`,
+			opts:         process.JQOptions{RawOutput: true},
+			expResult:    "b\n1\n{\"c\":\"d\"}",
+		},
+
+		"Join output should render strings without quotes and without newlines.": {
+			jqExpression: `.a[]`,
+			inputData:    `{"a": ["b", 1, {"c": "d"}]}`,
+			opts:         process.JQOptions{JoinOutput: true},
+			expResult:    `b1{"c":"d"}`,
+		},
+
+		"The objects keys should always be rendered sorted.": {
+			jqExpression: `.`,
+			inputData:    `{"b": 1, "a": {"d": 2, "c": 3}}`,
+			expResult:    `{"a":{"c":3,"d":2},"b":1}`,
+		},
+
+		"ASCII output should escape non ASCII characters.": {
+			jqExpression: `.`,
+			inputData:    `{"a": "ñ😀"}`,
+			opts:         process.JQOptions{ASCIIOutput: true},
+			expResult:    `{"a":"\u00f1\ud83d\ude00"}`,
+		},
+
+		"ASCII output should escape non ASCII characters on raw output.": {
+			jqExpression: `.a`,
+			inputData:    `{"a": "ñ"}`,
+			opts:         process.JQOptions{ASCIIOutput: true, RawOutput: true},
+			expResult:    `\u00f1`,
+		},
+
+		"Indent should render the results indented with spaces.": {
+			jqExpression: `.`,
+			inputData:    `{"a": [1]}`,
+			opts:         process.JQOptions{Indent: 3, Pretty: true},
+			expResult: `{
+   "a": [
+      1
+   ]
+}`,
+		},
+
+		"Invalid indent should fail.": {
+			jqExpression: `.`,
+			inputData:    `{}`,
+			opts:         process.JQOptions{Indent: 8},
+			expLoadErr:   true,
+		},
+
+		"NDJSON input should execute the expression on every input.": {
+			jqExpression: `.a`,
+			inputData:    "{\"a\": 1}\n{\"a\": 2}\n{\"a\": 3}\n",
+			expResult:    "1\n2\n3",
+		},
+
+		"Input builtin should consume the next input.": {
+			jqExpression: `[., input]`,
+			inputData:    "1\n2\n3\n4",
+			expResult:    "[1,2]\n[3,4]",
+		},
+
+		"Slurp should read all the inputs into an array.": {
+			jqExpression: `map(.a) | add`,
+			inputData:    "{\"a\": 1}\n{\"a\": 2}\n{\"a\": 3}",
+			opts:         process.JQOptions{Slurp: true},
+			expResult:    "6",
+		},
+
+		"Raw input should read every line as a string.": {
+			jqExpression: `ascii_upcase`,
+			inputData:    "a\nb\n",
+			opts:         process.JQOptions{RawInput: true},
+			expResult:    "\"A\"\n\"B\"",
+		},
+
+		"Raw input with slurp should read all the input data as a string.": {
+			jqExpression: `split("\n")`,
+			inputData:    "a\nb",
+			opts:         process.JQOptions{RawInput: true, Slurp: true},
+			expResult:    `["a","b"]`,
+		},
+
+		"Null input should execute the expression once with null.": {
+			jqExpression: `{"x": $x, "input": .}`,
+			inputData:    "{",
+			metadata:     map[string]any{"x": "y"},
+			opts:         process.JQOptions{NullInput: true},
+			expResult:    `{"input":null,"x":"y"}`,
+		},
+
+		"Null input with inputs builtin should consume all the inputs.": {
+			jqExpression: `[inputs.a] | add`,
+			inputData:    "{\"a\": 1}\n{\"a\": 2}",
+			opts:         process.JQOptions{NullInput: true},
+			expResult:    "3",
+		},
+
+		"Null input with slurp should read the inputs as an array.": {
+			jqExpression: `input | length`,
+			inputData:    "1 2 3",
+			opts:         process.JQOptions{NullInput: true, Slurp: true},
+			expResult:    "3",
+		},
+
+		"Null input with raw input should read the lines.": {
+			jqExpression: `[inputs]`,
+			inputData:    "a\nb",
+			opts:         process.JQOptions{NullInput: true, RawInput: true},
+			expResult:    `["a","b"]`,
+		},
+
+		"An invalid input after valid inputs should fail.": {
+			jqExpression: `.`,
+			inputData:    "{}\n{\"a\" b}",
+			expErr:       true,
+		},
+
 		"An invalid input should fail.": {
 			jqExpression: `.`,
 			inputData:    `{"a" b"}`,
 			expErr:       true,
 		},
 
+		"Imported modules should be used by the expression.": {
+			opts: process.JQOptions{Modules: map[string]string{
+				"lib": `def double: . * 2;`,
+			}},
+			jqExpression: `import "lib" as lib; .a | lib::double`,
+			inputData:    `{"a": 21}`,
+			expResult:    `42`,
+		},
+
+		"Included modules should be used by the expression.": {
+			opts: process.JQOptions{Modules: map[string]string{
+				"lib":    `include "common"; def greet: "hello " + name;`,
+				"common": `def name: .name;`,
+			}},
+			jqExpression: `include "lib"; greet`,
+			inputData:    `{"name": "world"}`,
+			expResult:    `"hello world"`,
+		},
+
+		"Missing modules should fail.": {
+			jqExpression: `import "lib" as lib; lib::double`,
+			inputData:    `{}`,
+			expLoadErr:   true,
+		},
+
+		"Invalid modules should fail.": {
+			opts:         process.JQOptions{Modules: map[string]string{"lib": `def double: . *;`}},
+			jqExpression: `.`,
+			inputData:    `{}`,
+			expLoadErr:   true,
+		},
+
+		"The sha256 function should return the hex encoded checksum.": {
+			jqExpression: `.a | sha256`,
+			inputData:    `{"a": "test"}`,
+			expResult:    `"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`,
+		},
+
+		"The sha256 function on non strings should fail.": {
+			jqExpression: `.a | sha256`,
+			inputData:    `{"a": 1}`,
+			expErr:       true,
+		},
+
+		"The yaml_decode and yaml_encode functions should decode and encode YAML.": {
+			jqExpression: `.a | yaml_decode | .b.c += 1 | ., yaml_encode`,
+			inputData:    `{"a": "b:\n  c: 1\n  d: [x, y]\n"}`,
+			expResult: `{"b":{"c":2,"d":["x","y"]}}
+"b:\n  c: 2\n  d:\n    - x\n    - \"y\"\n"`,
+		},
+
+		"The yaml_decode function on invalid YAML should fail.": {
+			jqExpression: `.a | yaml_decode`,
+			inputData:    `{"a": "b: [c"}`,
+			expErr:       true,
+		},
+
+		"The toml_decode function should decode TOML.": {
+			jqExpression: `.a | toml_decode`,
+			inputData:    `{"a": "title = \"test\"\n[owner]\ndob = 1979-05-27T07:32:00Z\n[[items]]\nid = 1\n[[items]]\nid = 2\n"}`,
+			expResult:    `{"items":[{"id":1},{"id":2}],"owner":{"dob":"1979-05-27T07:32:00Z"},"title":"test"}`,
+		},
+
+		"The toml_decode function on invalid TOML should fail.": {
+			jqExpression: `.a | toml_decode`,
+			inputData:    `{"a": "title = "}`,
+			expErr:       true,
+		},
+
+		"The semver_compare function should compare semantic versions.": {
+			jqExpression: `[.[] | semver_compare("1.10.0")]`,
+			inputData:    `["1.2.0", "v1.10.0", "1.10.1", "1.10.0-rc.1"]`,
+			expResult:    `[-1,0,1,-1]`,
+		},
+
+		"The semver_compare function on invalid versions should fail.": {
+			jqExpression: `semver_compare("1.0.0")`,
+			inputData:    `"not-a-version"`,
+			expErr:       true,
+		},
+
+		"The cidrsubnet and cidrcontains functions should calculate network prefixes.": {
+			jqExpression: `cidrsubnet(8; 2), cidrcontains("10.0.1.5"), cidrcontains("10.1.0.5"), cidrcontains("10.0.2.0/24"), cidrcontains("10.0.0.0/8")`,
+			inputData:    `"10.0.0.0/16"`,
+			expResult:    "\"10.0.2.0/24\"\ntrue\nfalse\ntrue\nfalse",
+		},
+
+		"The cidrsubnet function with a subnet out of the prefix should fail.": {
+			jqExpression: `cidrsubnet(8; 256)`,
+			inputData:    `"10.0.0.0/16"`,
+			expErr:       true,
+		},
+
+		"The uuid_v5 function should return name based UUIDs.": {
+			jqExpression: `uuid_v5("dns"), uuid_v5("6ba7b810-9dad-11d1-80b4-00c04fd430c8")`,
+			inputData:    `"www.example.com"`,
+			expResult:    "\"2ed6657d-e927-568b-95e1-2665a8aea6a2\"\n\"2ed6657d-e927-568b-95e1-2665a8aea6a2\"",
+		},
+
+		"The uuid_v5 function with an invalid namespace should fail.": {
+			jqExpression: `uuid_v5("wrong")`,
+			inputData:    `"www.example.com"`,
+			expErr:       true,
+		},
+
+		"The strftime_tz function should format times on time zones.": {
+			jqExpression: `(.[] | strftime_tz("%Y-%m-%d %H:%M %Z"; "Europe/Madrid")), (.[0] | strftime_tz("%H:%M"; "America/New_York"))`,
+			inputData:    `["2022-08-11T10:00:00Z", 1660212000]`,
+			expResult:    "\"2022-08-11 12:00 CEST\"\n\"2022-08-11 12:00 CEST\"\n\"06:00\"",
+		},
+
+		"The strftime_tz function with an invalid time zone should fail.": {
+			jqExpression: `strftime_tz("%Y"; "Europe/Missing")`,
+			inputData:    `1660212000`,
+			expErr:       true,
+		},
+
 		"Simple JQ should execute correctly.": {
 			jqExpression: `[.results[] | {name, age}]`,
 			inputData:    `{"timestamp": 1234567890,"report": "Age Report","results": [{ "name": "John", "age": 43, "city": "TownA" },{ "name": "Joe",  "age": 10, "city": "TownB" }]}`,
@@ -51,7 +309,7 @@ func TestJQPorcessorProcess(t *testing.T) {
 		},
 
 		"Pretty result JQ should execute correctly and in a pretty format.": {
-			pretty:       true,
+			opts:         process.JQOptions{Pretty: true},
 			jqExpression: `[.results[] | {name, age}]`,
 			inputData:    `{"timestamp": 1234567890,"report": "Age Report","results": [{ "name": "John", "age": 43, "city": "TownA" },{ "name": "Joe",  "age": 10, "city": "TownB" }]}`,
 			expResult: `[
@@ -150,7 +408,11 @@ func TestJQPorcessorProcess(t *testing.T) {
 			assert := assert.New(t)
 			require := require.New(t)
 
-			jq, err := process.NewJQProcessor(context.TODO(), test.jqExpression, test.metadata, test.pretty)
+			jq, err := process.NewJQProcessor(context.TODO(), test.jqExpression, test.metadata, test.opts)
+			if test.expLoadErr {
+				assert.Error(err)
+				return
+			}
 			require.NoError(err)
 
 			gotRes, err := jq.Process(context.TODO(), test.inputData)
@@ -163,3 +425,193 @@ func TestJQPorcessorProcess(t *testing.T) {
 		})
 	}
 }
+
+func TestJQPorcessorLibraryPaths(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "lib.jq"), []byte(`def double: . * 2;`), 0o600)
+	require.NoError(err)
+	err = os.WriteFile(filepath.Join(dir, "data.json"), []byte(`{"factor": 3}`), 0o600)
+	require.NoError(err)
+
+	opts := process.JQOptions{
+		LibraryPaths: []string{dir},
+		// Inline modules and library paths can be used together.
+		Modules: map[string]string{"other": `def double: . * 4;`},
+	}
+	jq, err := process.NewJQProcessor(context.TODO(), `import "lib" as lib; import "other" as other; import "data" as $data; [(.a | lib::double), (.a | other::double), .a * $data[0].factor]`, nil, opts)
+	require.NoError(err)
+
+	gotRes, err := jq.Process(context.TODO(), `{"a": 2}`)
+	require.NoError(err)
+	assert.Equal(`[4,8,6]`, gotRes)
+}
+
+func TestJQLibraryModules(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "lib.jq"), []byte(`def double: . * 2;`), 0o600)
+	require.NoError(err)
+	err = os.WriteFile(filepath.Join(dir, "data.json"), []byte(`{"factor": 3}`), 0o600)
+	require.NoError(err)
+
+	expression := `import "lib" as lib; import "other" as other; import "data" as $data; (.a | lib::double) * $data[0].factor * $x`
+	opts := process.JQOptions{
+		LibraryPaths: []string{dir},
+		Modules:      map[string]string{"other": `def double: . * 4;`},
+	}
+	modules, err := process.JQLibraryModules(expression, map[string]any{"x": 1}, opts)
+	require.NoError(err)
+
+	// The inline modules are not loaded from the library paths.
+	expModules := map[string]string{"lib": "def double: . * 2; .", "data.json": `[{"factor":3}]`}
+	assert.Equal(expModules, modules)
+
+	// The library modules change when their files change.
+	err = os.WriteFile(filepath.Join(dir, "lib.jq"), []byte(`def double: . * 3;`), 0o600)
+	require.NoError(err)
+	modules, err = process.JQLibraryModules(expression, map[string]any{"x": 1}, opts)
+	require.NoError(err)
+	assert.Equal("def double: . * 3; .", modules["lib"])
+}
+
+func TestJQPorcessorProcessResults(t *testing.T) {
+	tests := map[string]struct {
+		opts         process.JQOptions
+		jqExpression string
+		inputData    string
+		expResult    string
+		expResults   []string
+		expValues    []any
+	}{
+		"A single result should return a single result.": {
+			jqExpression: `.a`,
+			inputData:    `{"a": {"b": 1}}`,
+			expResult:    `{"b":1}`,
+			expResults:   []string{`{"b":1}`},
+			expValues:    []any{map[string]any{"b": 1.0}},
+		},
+
+		"No results should return empty results.": {
+			jqExpression: `empty`,
+			inputData:    `{}`,
+			expResult:    ``,
+			expResults:   []string{},
+			expValues:    []any{},
+		},
+
+		"Multiple results should return every result.": {
+			jqExpression: `.[]`,
+			inputData:    `[1, "a", {"b": [2]}]`,
+			expResult:    "1\n\"a\"\n{\"b\":[2]}",
+			expResults:   []string{`1`, `"a"`, `{"b":[2]}`},
+			expValues:    []any{1.0, "a", map[string]any{"b": []any{2.0}}},
+		},
+
+		"Multiple results with output options should return every result rendered.": {
+			opts:         process.JQOptions{JoinOutput: true},
+			jqExpression: `.[]`,
+			inputData:    `["a", "b", 1]`,
+			expResult:    "ab1",
+			expResults:   []string{"a", "b", "1"},
+			expValues:    []any{"a", "b", 1.0},
+		},
+
+		"Multiple inputs should return the results of every input.": {
+			jqExpression: `.a`,
+			inputData:    `{"a": 1} {"a": 2}`,
+			expResult:    "1\n2",
+			expResults:   []string{"1", "2"},
+			expValues:    []any{1.0, 2.0},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			jq, err := process.NewJQProcessor(context.TODO(), test.jqExpression, nil, test.opts)
+			require.NoError(err)
+
+			gotRes, err := jq.ProcessResults(context.TODO(), test.inputData)
+			if assert.NoError(err) {
+				assert.Equal(test.expResult, gotRes.Result)
+				assert.Equal(test.expResults, gotRes.Results)
+				assert.Equal(test.expValues, gotRes.Values)
+			}
+		})
+	}
+}
+
+func TestJQPorcessorProcessValue(t *testing.T) {
+	tests := map[string]struct {
+		opts         process.JQOptions
+		jqExpression string
+		input        any
+		expResults   process.Results
+		expErr       bool
+	}{
+		"The input value should be used as the input.": {
+			jqExpression: `.a[] | . + 1`,
+			input:        map[string]any{"a": []any{1, 2.5}},
+			expResults: process.Results{
+				Result:  "2\n3.5",
+				Results: []string{"2", "3.5"},
+				Values:  []any{2, 3.5},
+			},
+		},
+
+		"Slurp should wrap the input value in an array.": {
+			opts:         process.JQOptions{Slurp: true},
+			jqExpression: `length`,
+			input:        "test",
+			expResults: process.Results{
+				Result:  "1",
+				Results: []string{"1"},
+				Values:  []any{1},
+			},
+		},
+
+		"Null input should read the input value with input.": {
+			opts:         process.JQOptions{NullInput: true},
+			jqExpression: `[., input]`,
+			input:        true,
+			expResults: process.Results{
+				Result:  "[null,true]",
+				Results: []string{"[null,true]"},
+				Values:  []any{[]any{nil, true}},
+			},
+		},
+
+		"Raw input should fail.": {
+			opts:         process.JQOptions{RawInput: true},
+			jqExpression: `.`,
+			input:        "test",
+			expErr:       true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			jq, err := process.NewJQValueProcessor(context.TODO(), test.jqExpression, nil, test.opts)
+			if test.expErr {
+				assert.Error(err)
+				return
+			}
+			require.NoError(err)
+
+			gotRes, err := jq.ProcessValue(context.TODO(), test.input)
+			if assert.NoError(err) {
+				assert.Equal(test.expResults, gotRes)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package process
+
+import (
+	"context"
+	"fmt"
+)
+
+// PipelineStepError is returned when a step of a pipeline fails.
+type PipelineStepError struct {
+	// Step is the index of the failed step.
+	Step int
+	Err  error
+}
+
+func (p PipelineStepError) Error() string {
+	return fmt.Sprintf("pipeline step %d failed: %s", p.Step, p.Err)
+}
+
+func (p PipelineStepError) Unwrap() error {
+	return p.Err
+}
+
+// NewPipelineProcessor returns a processor that executes the processors in order, the result of each
+// processor is the input data of the next one. If a processor fails, the pipeline stops and returns
+// a PipelineStepError.
+func NewPipelineProcessor(processors ...Processor) Processor {
+	return ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+		data := inputData
+		for i, p := range processors {
+			result, err := p.Process(ctx, data)
+			if err != nil {
+				return "", PipelineStepError{Step: i, Err: err}
+			}
+			data = result
+		}
+
+		return data, nil
+	})
+}
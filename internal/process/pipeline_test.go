@@ -0,0 +1,71 @@
+package process_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+)
+
+func TestPipelineProcessorProcess(t *testing.T) {
+	suffix := func(s string) process.Processor {
+		return process.ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+			return inputData + s, nil
+		})
+	}
+
+	tests := map[string]struct {
+		processors []process.Processor
+		expResult  string
+		expErr     error
+	}{
+		"A pipeline without processors should return the input data.": {
+			expResult: `"test"`,
+		},
+
+		"A pipeline should execute the processors in order with the previous result.": {
+			processors: []process.Processor{suffix("-1"), suffix("-2"), suffix("-3")},
+			expResult:  `"test"-1-2-3`,
+		},
+
+		"A failed processor should stop the pipeline and return the failed step.": {
+			processors: []process.Processor{
+				suffix("-1"),
+				process.ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+					return "", fmt.Errorf("something")
+				}),
+				process.ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+					panic("should not be executed")
+				}),
+			},
+			expErr: process.PipelineStepError{Step: 1, Err: fmt.Errorf("something")},
+		},
+
+		"A pipeline should work with real processors.": {
+			processors: func() []process.Processor {
+				jq, _ := process.NewJQProcessor(context.TODO(), `{"a": .}`, nil, process.JQOptions{})
+				yq, _ := process.NewYQProcessor(context.TODO(), `.a`, nil, process.YQOptions{})
+				return []process.Processor{jq, yq}
+			}(),
+			expResult: "test",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p := process.NewPipelineProcessor(test.processors...)
+			gotRes, err := p.Process(context.TODO(), `"test"`)
+
+			if test.expErr != nil {
+				assert.Equal(test.expErr, err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expResult, gotRes)
+			}
+		})
+	}
+}
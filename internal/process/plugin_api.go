@@ -0,0 +1,28 @@
+package process
+
+import (
+	"reflect"
+
+	"github.com/traefik/yaegi/interp"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+// PluginAPIPackage is the import path of the plugin API package.
+const PluginAPIPackage = "github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+
+// pluginAPISymbols are the Yaegi symbols of the plugin API package, they are available to all the plugins.
+var pluginAPISymbols = interp.Exports{
+	PluginAPIPackage + "/dataprocessor": {
+		"AddFinding":      reflect.ValueOf(dataprocessor.AddFinding),
+		"AddWarning":      reflect.ValueOf(dataprocessor.AddWarning),
+		"Debug":           reflect.ValueOf(dataprocessor.Debug),
+		"Error":           reflect.ValueOf(dataprocessor.Error),
+		"Finding":         reflect.ValueOf((*dataprocessor.Finding)(nil)),
+		"Info":            reflect.ValueOf(dataprocessor.Info),
+		"Severity":        reflect.ValueOf((*dataprocessor.Severity)(nil)),
+		"SeverityError":   reflect.ValueOf(dataprocessor.SeverityError),
+		"SeverityWarning": reflect.ValueOf(dataprocessor.SeverityWarning),
+		"Warn":            reflect.ValueOf(dataprocessor.Warn),
+	},
+}
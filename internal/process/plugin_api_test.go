@@ -0,0 +1,192 @@
+package process_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+func TestGoPluginFindings(t *testing.T) {
+	tests := map[string]struct {
+		plugin      string
+		sandbox     process.GoPluginSandbox
+		input       any
+		expFindings []dataprocessor.Finding
+		expErr      bool
+	}{
+		"A plugin without findings should not report findings.": {
+			plugin: `
+package testplugin
+
+import "context"
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	return input, nil
+}
+`,
+			input:       map[string]any{"a": "b"},
+			expFindings: []dataprocessor.Finding{},
+		},
+
+		"The plugin findings should be reported in order.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	if input.(map[string]any)["replicas"] == 1 {
+		dataprocessor.AddFinding(ctx, dataprocessor.Finding{Severity: dataprocessor.SeverityWarning, Summary: "Single replica", Path: "replicas"})
+	}
+	dataprocessor.AddFinding(ctx, dataprocessor.Finding{Severity: dataprocessor.SeverityError, Summary: "Missing name", Detail: "name is required"})
+	return input, nil
+}
+`,
+			input: map[string]any{"replicas": 1},
+			expFindings: []dataprocessor.Finding{
+				{Severity: dataprocessor.SeverityWarning, Summary: "Single replica", Path: "replicas"},
+				{Severity: dataprocessor.SeverityError, Summary: "Missing name", Detail: "name is required"},
+			},
+		},
+
+		"The findings without a known severity should be errors.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	dataprocessor.AddFinding(ctx, dataprocessor.Finding{Summary: "No severity"})
+	dataprocessor.AddFinding(ctx, dataprocessor.Finding{Severity: "info", Summary: "Unknown severity"})
+	return input, nil
+}
+`,
+			expFindings: []dataprocessor.Finding{
+				{Severity: dataprocessor.SeverityError, Summary: "No severity"},
+				{Severity: dataprocessor.SeverityError, Summary: "Unknown severity"},
+			},
+		},
+
+		"The findings should be reported even if the plugin fails.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	dataprocessor.AddFinding(ctx, dataprocessor.Finding{Summary: "Invalid input"})
+	return nil, fmt.Errorf("invalid input")
+}
+`,
+			expFindings: []dataprocessor.Finding{
+				{Severity: dataprocessor.SeverityError, Summary: "Invalid input"},
+			},
+			expErr: true,
+		},
+
+		"The plugin API should be allowed by the safe sandbox.": {
+			plugin: `
+package testplugin
+
+import (
+	"context"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	dataprocessor.AddFinding(ctx, dataprocessor.Finding{Severity: dataprocessor.SeverityWarning, Summary: "Sandboxed"})
+	return input, nil
+}
+`,
+			sandbox: process.GoPluginSandbox{Preset: process.SandboxPresetSafe},
+			expFindings: []dataprocessor.Finding{
+				{Severity: dataprocessor.SeverityWarning, Summary: "Sandboxed"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			p, err := process.NewGoPluginV2ValueProcessor(context.TODO(), test.plugin, nil, test.sandbox)
+			require.NoError(err)
+
+			recorder := &dataprocessor.Recorder{}
+			_, err = p.ProcessValue(dataprocessor.ContextWithRecorder(context.TODO(), recorder), test.input)
+			if test.expErr {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+			assert.Equal(test.expFindings, recorder.Findings())
+		})
+	}
+}
+
+func TestGoPluginLogs(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	plugin := `
+package testplugin
+
+import (
+	"context"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	dataprocessor.Debug(ctx, "processing input", map[string]any{"size": len(inputData)})
+	dataprocessor.Warn(ctx, "deprecated input")
+	dataprocessor.AddWarning(ctx, "Deprecated input", "the input will not be supported")
+	return inputData, nil
+}
+`
+	p, err := process.NewGoPluginV1Processor(context.TODO(), plugin, nil, process.GoPluginSandbox{Preset: process.SandboxPresetSafe})
+	require.NoError(err)
+
+	var out bytes.Buffer
+	ctx := tflogtest.RootLogger(context.TODO(), &out)
+	recorder := &dataprocessor.Recorder{}
+	_, err = p.Process(dataprocessor.ContextWithRecorder(ctx, recorder), "test")
+	require.NoError(err)
+
+	logs, err := tflogtest.MultilineJSONDecode(&out)
+	require.NoError(err)
+	require.Len(logs, 2)
+	assert.Equal("debug", logs[0]["@level"])
+	assert.Equal("processing input", logs[0]["@message"])
+	assert.Equal(float64(4), logs[0]["size"])
+	assert.Equal(true, logs[0]["dataprocessor_plugin"])
+	assert.Equal("warn", logs[1]["@level"])
+	assert.Equal("deprecated input", logs[1]["@message"])
+
+	expFindings := []dataprocessor.Finding{
+		{Severity: dataprocessor.SeverityWarning, Summary: "Deprecated input", Detail: "the input will not be supported"},
+	}
+	assert.Equal(expFindings, recorder.Findings())
+}
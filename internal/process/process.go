@@ -13,3 +13,46 @@ type ProcessorFunc func(ctx context.Context, inputData string) (result string, e
 func (p ProcessorFunc) Process(ctx context.Context, inputData string) (result string, err error) {
 	return p(ctx, inputData)
 }
+
+// Results are the results of a processor execution that can have multiple results.
+type Results struct {
+	// Result is the joined result, the same as Process returns.
+	Result string
+	// Results are every result rendered independently.
+	Results []string
+	// Values are every result decoded, using the same data model as decoded JSON (e.g `map[string]any`,
+	// `[]any`, `string`...).
+	Values []any
+}
+
+// MultiResultProcessor is a Processor that can also return every result independently (e.g the values
+// emitted by a JQ expression or the documents of a YQ result), together with the joined result.
+type MultiResultProcessor interface {
+	Processor
+	ProcessResults(ctx context.Context, inputData string) (Results, error)
+}
+
+// MultiResultProcessorFunc its a helper type to create MultiResultProcessors with a single function.
+type MultiResultProcessorFunc func(ctx context.Context, inputData string) (Results, error)
+
+func (p MultiResultProcessorFunc) Process(ctx context.Context, inputData string) (result string, err error) {
+	res, err := p(ctx, inputData)
+	return res.Result, err
+}
+
+func (p MultiResultProcessorFunc) ProcessResults(ctx context.Context, inputData string) (Results, error) {
+	return p(ctx, inputData)
+}
+
+// ValueProcessor knows how to process already decoded input data, using the same data model as decoded
+// JSON (e.g `map[string]any`, `[]any`, `string`...), so the input doesn't need to be encoded and decoded again.
+type ValueProcessor interface {
+	ProcessValue(ctx context.Context, input any) (Results, error)
+}
+
+// ValueProcessorFunc its a helper type to create ValueProcessors with a single function.
+type ValueProcessorFunc func(ctx context.Context, input any) (Results, error)
+
+func (p ValueProcessorFunc) ProcessValue(ctx context.Context, input any) (Results, error) {
+	return p(ctx, input)
+}
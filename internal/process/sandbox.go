@@ -0,0 +1,246 @@
+package process
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+)
+
+// SandboxPreset is a predefined set of Go standard library packages and symbols that will
+// be exposed to the Go plugins.
+type SandboxPreset string
+
+const (
+	// SandboxPresetUnrestricted exposes all the Go standard library supported by Yaegi.
+	SandboxPresetUnrestricted SandboxPreset = "unrestricted"
+	// SandboxPresetSafe exposes only the Go standard library packages and symbols that can't
+	// execute commands, access the network, access the filesystem or the environment.
+	SandboxPresetSafe SandboxPreset = "safe"
+)
+
+// SandboxPresets are all the supported sandbox presets.
+var SandboxPresets = []SandboxPreset{SandboxPresetUnrestricted, SandboxPresetSafe}
+
+// GoPluginSandbox is the policy that decides what Go standard library packages and symbols
+// are exposed to a Go plugin.
+type GoPluginSandbox struct {
+	// Preset is the base set of allowed packages and symbols, by default unrestricted.
+	Preset SandboxPreset
+	// Allow are packages (e.g `net/http`) or symbols (e.g `os.WriteFile`) that will be
+	// allowed on top of the preset ones.
+	Allow []string
+}
+
+// SandboxError is returned when a plugin uses a package that is not allowed by the sandbox.
+type SandboxError struct {
+	Package string
+	Preset  SandboxPreset
+}
+
+func (s SandboxError) Error() string {
+	return fmt.Sprintf("package %q is not allowed by the %q sandbox", s.Package, s.Preset)
+}
+
+// safePackages are the packages that are fully allowed by the safe preset.
+var safePackages = []string{
+	"archive/tar", "archive/zip", "bufio", "bytes",
+	"compress/bzip2", "compress/flate", "compress/gzip", "compress/lzw", "compress/zlib",
+	"container/heap", "container/list", "container/ring", "context",
+	"crypto", "crypto/aes", "crypto/cipher", "crypto/des", "crypto/dsa", "crypto/ecdsa", "crypto/ed25519",
+	"crypto/elliptic", "crypto/hmac", "crypto/md5", "crypto/rand", "crypto/rc4", "crypto/rsa", "crypto/sha1",
+	"crypto/sha256", "crypto/sha512", "crypto/subtle", "crypto/x509", "crypto/x509/pkix",
+	"encoding", "encoding/ascii85", "encoding/asn1", "encoding/base32", "encoding/base64", "encoding/binary",
+	"encoding/csv", "encoding/gob", "encoding/hex", "encoding/json", "encoding/pem", "encoding/xml",
+	"errors", "fmt",
+	"go/ast", "go/constant", "go/doc", "go/format", "go/parser", "go/printer", "go/scanner", "go/token",
+	"hash", "hash/adler32", "hash/crc32", "hash/crc64", "hash/fnv", "hash/maphash",
+	"html", "image", "image/color", "image/color/palette", "image/draw", "image/gif",
+	"image/jpeg", "image/png", "index/suffixarray", "io", "io/fs", "log",
+	"math", "math/big", "math/bits", "math/cmplx", "math/rand",
+	"mime", "mime/quotedprintable", "net/mail", "net/netip", "net/url",
+	"path", "path/filepath", "reflect", "regexp", "regexp/syntax", "sort", "strconv", "strings",
+	"sync", "sync/atomic", "text/scanner", "text/tabwriter", "text/template/parse",
+	"time", "unicode", "unicode/utf16", "unicode/utf8",
+}
+
+// safeSymbols are the symbols allowed by the safe preset on packages that are not fully allowed.
+//
+// The symbols that access the filesystem by path (e.g `os.ReadFile` or `os.Stat`) are not allowed, the plugins
+// could read any file of the provider process, like `/proc/self/environ` that has the provider environment.
+var safeSymbols = map[string][]string{
+	"os": {
+		"DevNull", "DirEntry", "ErrClosed", "ErrDeadlineExceeded", "ErrExist", "ErrInvalid",
+		"ErrNoDeadline", "ErrNotExist", "ErrPermission", "ErrProcessDone", "FileInfo", "FileMode",
+		"Getpagesize", "IsExist", "IsNotExist", "IsPathSeparator", "IsPermission", "IsTimeout",
+		"LinkError", "ModeAppend", "ModeCharDevice", "ModeDevice", "ModeDir", "ModeExclusive",
+		"ModeIrregular", "ModeNamedPipe", "ModePerm", "ModeSetgid", "ModeSetuid", "ModeSocket", "ModeSticky",
+		"ModeSymlink", "ModeTemporary", "ModeType", "PathError", "PathListSeparator", "PathSeparator",
+		"SameFile", "SyscallError",
+	},
+	"io/ioutil": {"Discard", "NopCloser", "ReadAll"},
+}
+
+// safeDeniedSymbols are the symbols of the packages fully allowed by the safe preset that access the filesystem
+// by path. The packages that have methods that access the filesystem (e.g `text/template` `ParseFiles`) are not
+// allowed by the safe preset, the methods can't be removed from their types.
+var safeDeniedSymbols = map[string][]string{
+	"archive/zip":   {"OpenReader"},
+	"go/parser":     {"ParseDir", "ParseExprFrom", "ParseFile"},
+	"path/filepath": {"Abs", "EvalSymlinks", "Glob", "Walk", "WalkDir"},
+}
+
+// symbols returns the Yaegi symbols allowed by the sandbox.
+func (g GoPluginSandbox) symbols() (interp.Exports, error) {
+	preset := g.preset()
+
+	// Index the stdlib symbols by import path.
+	stdlibByPath := map[string]string{}
+	for k := range stdlib.Symbols {
+		stdlibByPath[path.Dir(k)] = k
+	}
+
+	allowedPkgs := map[string]bool{}
+	allowedSymbols := map[string]map[string]bool{}
+	allowSymbols := func(pkg string, symbols ...string) {
+		if allowedSymbols[pkg] == nil {
+			allowedSymbols[pkg] = map[string]bool{}
+		}
+		for _, s := range symbols {
+			allowedSymbols[pkg][s] = true
+		}
+	}
+	deniedSymbols := map[string]map[string]bool{}
+
+	switch preset {
+	case SandboxPresetUnrestricted:
+		return stdlib.Symbols, nil
+	case SandboxPresetSafe:
+		for _, p := range safePackages {
+			allowedPkgs[p] = true
+		}
+		for p, symbols := range safeSymbols {
+			allowSymbols(p, symbols...)
+		}
+		for p, symbols := range safeDeniedSymbols {
+			deniedSymbols[p] = map[string]bool{}
+			for _, s := range symbols {
+				deniedSymbols[p][s] = true
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown sandbox preset %q", preset)
+	}
+
+	for _, a := range g.Allow {
+		pkg, symbol := splitSandboxAllow(a)
+		if _, ok := stdlibByPath[pkg]; !ok {
+			return nil, fmt.Errorf("unknown package %q on sandbox allow list", pkg)
+		}
+
+		if symbol == "" {
+			allowedPkgs[pkg] = true
+			delete(deniedSymbols, pkg)
+			continue
+		}
+
+		key := stdlibByPath[pkg]
+		if _, ok := stdlib.Symbols[key][symbol]; !ok {
+			return nil, fmt.Errorf("unknown symbol %q on sandbox allow list", a)
+		}
+		allowSymbols(pkg, symbol)
+		delete(deniedSymbols[pkg], symbol)
+	}
+
+	exports := interp.Exports{}
+	for pkg, key := range stdlibByPath {
+		if allowedPkgs[pkg] && len(deniedSymbols[pkg]) == 0 {
+			exports[key] = stdlib.Symbols[key]
+			continue
+		}
+
+		if allowedPkgs[pkg] {
+			pkgExports := map[string]reflect.Value{}
+			for name, v := range stdlib.Symbols[key] {
+				if !deniedSymbols[pkg][name] {
+					pkgExports[name] = v
+				}
+			}
+			exports[key] = pkgExports
+			continue
+		}
+
+		symbols, ok := allowedSymbols[pkg]
+		if !ok {
+			continue
+		}
+
+		pkgExports := map[string]reflect.Value{}
+		for name, v := range stdlib.Symbols[key] {
+			// Yaegi interface wrappers are required to use the package types.
+			if symbols[name] || strings.HasPrefix(name, "_") {
+				pkgExports[name] = v
+			}
+		}
+		exports[key] = pkgExports
+	}
+
+	return exports, nil
+}
+
+// checkImports checks the plugin source code only imports packages allowed by the sandbox.
+func (g GoPluginSandbox) checkImports(src string, exports interp.Exports) error {
+	// Without restrictions, Yaegi will already fail on the unknown packages.
+	if g.preset() == SandboxPresetUnrestricted {
+		return nil
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ImportsOnly)
+	if err != nil {
+		return fmt.Errorf("could not parse plugin source code: %w", positionedSourceError(src, err))
+	}
+
+	// The plugin API is always allowed.
+	allowed := map[string]bool{PluginAPIPackage: true}
+	for k := range exports {
+		allowed[path.Dir(k)] = true
+	}
+
+	for _, imp := range f.Imports {
+		pkg, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return fmt.Errorf("invalid import %s: %w", imp.Path.Value, err)
+		}
+
+		if !allowed[pkg] {
+			return SandboxError{Package: pkg, Preset: g.preset()}
+		}
+	}
+
+	return nil
+}
+
+func (g GoPluginSandbox) preset() SandboxPreset {
+	if g.Preset == "" {
+		return SandboxPresetUnrestricted
+	}
+	return g.Preset
+}
+
+// splitSandboxAllow splits a sandbox allow entry in package and symbol, e.g:
+// `net/http` is the `net/http` package and `os.WriteFile` is the `WriteFile` symbol of `os` package.
+func splitSandboxAllow(s string) (pkg, symbol string) {
+	dir, base := path.Split(s)
+	i := strings.LastIndex(base, ".")
+	if i < 0 {
+		return s, ""
+	}
+
+	return dir + base[:i], base[i+1:]
+}
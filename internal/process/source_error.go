@@ -0,0 +1,100 @@
+package process
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SourceError is returned when the source of a processor (e.g a JQ expression or a Go plugin source code)
+// is invalid at a known position.
+type SourceError struct {
+	// Source is the name of the source with the error when it's not the processor main source (e.g a JQ
+	// module name), empty otherwise.
+	Source string
+	// Line is the line of the error, starting at 1.
+	Line int
+	// Column is the column of the error on the line, starting at 1.
+	Column int
+	// Msg is the error message, without the position.
+	Msg string
+	// LineSource is the source code of the error line.
+	LineSource string
+}
+
+func (s SourceError) Error() string {
+	if s.Source != "" {
+		return fmt.Sprintf("%s:%d:%d: %s", s.Source, s.Line, s.Column, s.Msg)
+	}
+	return fmt.Sprintf("%d:%d: %s", s.Line, s.Column, s.Msg)
+}
+
+// Snippet returns the source code line of the error with a caret pointing to the error column, e.g:
+//
+//	6 | 	return foo, nil
+//	  | 	       ^
+func (s SourceError) Snippet() string {
+	line := strconv.Itoa(s.Line)
+
+	// Keep the tabs so the caret is aligned with the source code.
+	var caret strings.Builder
+	for _, r := range s.LineSource[:s.Column-1] {
+		if r == '\t' {
+			caret.WriteRune('\t')
+			continue
+		}
+		caret.WriteRune(' ')
+	}
+	caret.WriteRune('^')
+
+	return fmt.Sprintf("%s | %s\n%s | %s", line, s.LineSource, strings.Repeat(" ", len(line)), caret.String())
+}
+
+// newSourceError returns the error of src at the line and column, the position is adjusted to src if it's
+// outside of it.
+func newSourceError(src string, line, column int, msg string) SourceError {
+	lines := strings.Split(src, "\n")
+	line = min(max(line, 1), len(lines))
+	lineSrc := strings.TrimSuffix(lines[line-1], "\r")
+	column = min(max(column, 1), len(lineSrc)+1)
+
+	// Don't point to the middle of a multibyte character.
+	for column > 1 && !isRuneStart(lineSrc, column-1) {
+		column--
+	}
+
+	return SourceError{Line: line, Column: column, Msg: msg, LineSource: lineSrc}
+}
+
+func isRuneStart(s string, i int) bool {
+	return i >= len(s) || s[i]&0xC0 != 0x80
+}
+
+// newSourceErrorFromOffset returns the error of src at the byte offset.
+func newSourceErrorFromOffset(src string, offset int, msg string) SourceError {
+	offset = min(max(offset, 0), len(src))
+	before := src[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := offset - strings.LastIndex(before, "\n")
+
+	return newSourceError(src, line, column, msg)
+}
+
+// positionedErrorRegexp matches the errors that start with their position, with an optional file name, like
+// the Yaegi (e.g `_.go:3:25: expected ')'`) and yq (e.g `1:6: invalid input text`) errors.
+var positionedErrorRegexp = regexp.MustCompile(`(?s)^(?:[^\s:]*:)?(\d+):(\d+): (.*)$`)
+
+// positionedSourceError returns a SourceError of src if err message starts with its position, otherwise
+// it returns err.
+func positionedSourceError(src string, err error) error {
+	match := positionedErrorRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+
+	line, _ := strconv.Atoi(match[1])
+	column, _ := strconv.Atoi(match[2])
+
+	return newSourceError(src, line, column, match[3])
+}
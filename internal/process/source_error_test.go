@@ -0,0 +1,170 @@
+package process_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+)
+
+func TestSourceError(t *testing.T) {
+	tests := map[string]struct {
+		newProcessor func(src string) error
+		src          string
+		expErr       *process.SourceError
+		expSnippet   string
+	}{
+		"An invalid JQ expression should have the position of the error.": {
+			newProcessor: newJQProcessor,
+			src:          ".a |\n  .b |)",
+			expErr:       &process.SourceError{Line: 2, Column: 7, Msg: `unexpected token ")"`, LineSource: "  .b |)"},
+			expSnippet:   "2 |   .b |)\n  |       ^",
+		},
+
+		"An incomplete JQ expression should point to the end of the expression.": {
+			newProcessor: newJQProcessor,
+			src:          ".a | ",
+			expErr:       &process.SourceError{Line: 1, Column: 6, Msg: "unexpected EOF", LineSource: ".a | "},
+			expSnippet:   "1 | .a | \n  |      ^",
+		},
+
+		"A JQ expression that can't be compiled should not have a position.": {
+			newProcessor: newJQProcessor,
+			src:          ".a | nonexistent(1)",
+		},
+
+		"An invalid JQ inline module should have the position of the error on the module.": {
+			newProcessor: newJQModuleProcessor,
+			src:          "def double:\n  . *;",
+			expErr:       &process.SourceError{Source: "lib", Line: 2, Column: 6, Msg: `unexpected token ";"`, LineSource: "  . *;"},
+			expSnippet:   "2 |   . *;\n  |      ^",
+		},
+
+		"An invalid JQ library path module should have the position of the error on the module.": {
+			newProcessor: newJQLibraryModuleProcessor,
+			src:          "def double:\n  . *;",
+			expErr:       &process.SourceError{Source: "lib", Line: 2, Column: 6, Msg: `unexpected token ";"`, LineSource: "  . *;"},
+			expSnippet:   "2 |   . *;\n  |      ^",
+		},
+
+		"An invalid YQ expression should have the position of the error.": {
+			newProcessor: newYQProcessor,
+			src:          `.a | "abc`,
+			expErr:       &process.SourceError{Line: 1, Column: 6, Msg: `invalid input text "\"abc"`, LineSource: `.a | "abc`},
+			expSnippet:   "1 | .a | \"abc\n  |      ^",
+		},
+
+		"A YQ expression error without position should not have a position.": {
+			newProcessor: newYQProcessor,
+			src:          ".a[",
+		},
+
+		"A Go plugin that can't be parsed should have the position of the error.": {
+			newProcessor: newGoPluginV1Processor,
+			src: `
+package testplugin
+
+func ProcessorPluginV1( {
+}
+`,
+			expErr:     &process.SourceError{Line: 4, Column: 25, Msg: "expected ')', found '{' (and 1 more errors)", LineSource: "func ProcessorPluginV1( {"},
+			expSnippet: "4 | func ProcessorPluginV1( {\n  |                         ^",
+		},
+
+		"A Go plugin with the safe sandbox that can't be parsed should have the position of the error.": {
+			newProcessor: newSafeGoPluginV1Processor,
+			src: `
+package testplugin
+
+import (
+	"context
+)
+`,
+			expErr:     &process.SourceError{Line: 5, Column: 2, Msg: "string literal not terminated", LineSource: "\t\"context"},
+			expSnippet: "5 | \t\"context\n  | \t^",
+		},
+
+		"A Go plugin that can't be compiled should have the position of the error keeping the indentation.": {
+			newProcessor: newGoPluginV1Processor,
+			src: `
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return missing, nil
+}
+`,
+			expErr:     &process.SourceError{Line: 7, Column: 9, Msg: "undefined: missing", LineSource: "\treturn missing, nil"},
+			expSnippet: "7 | \treturn missing, nil\n  | \t       ^",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			err := test.newProcessor(test.src)
+			assert.Error(err)
+
+			var srcErr process.SourceError
+			if test.expErr == nil {
+				assert.False(errors.As(err, &srcErr))
+				return
+			}
+
+			if assert.ErrorAs(err, &srcErr) {
+				assert.Equal(*test.expErr, srcErr)
+				assert.Equal(test.expSnippet, srcErr.Snippet())
+			}
+		})
+	}
+}
+
+func newJQProcessor(src string) error {
+	_, err := process.NewJQProcessor(context.TODO(), src, nil, process.JQOptions{})
+	return err
+}
+
+func newJQModuleProcessor(src string) error {
+	opts := process.JQOptions{Modules: map[string]string{"lib": src}}
+	_, err := process.NewJQProcessor(context.TODO(), `import "lib" as lib; .`, nil, opts)
+	return err
+}
+
+func newJQLibraryModuleProcessor(src string) error {
+	dir, err := os.MkdirTemp("", "jq-lib")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.WriteFile(filepath.Join(dir, "lib.jq"), []byte(src), 0o600)
+	if err != nil {
+		return err
+	}
+
+	opts := process.JQOptions{LibraryPaths: []string{dir}}
+	_, err = process.NewJQProcessor(context.TODO(), `import "lib" as lib; .`, nil, opts)
+	return err
+}
+
+func newYQProcessor(src string) error {
+	_, err := process.NewYQProcessor(context.TODO(), src, nil, process.YQOptions{})
+	return err
+}
+
+func newGoPluginV1Processor(src string) error {
+	_, err := process.NewGoPluginV1Processor(context.TODO(), src, nil, process.GoPluginSandbox{})
+	return err
+}
+
+func newSafeGoPluginV1Processor(src string) error {
+	_, err := process.NewGoPluginV1Processor(context.TODO(), src, nil, process.GoPluginSandbox{Preset: process.SandboxPresetSafe})
+	return err
+}
@@ -0,0 +1,108 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutError is returned when a processor execution takes more time than the allowed one.
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (t TimeoutError) Error() string {
+	return fmt.Sprintf("processor timed out after %s", t.Timeout)
+}
+
+// NewTimeoutProcessor wraps a processor setting a deadline on every execution, if the execution
+// takes more than the timeout a TimeoutError will be returned.
+//
+// The wrapped processor context will be cancelled so it can stop its execution, however the result
+// will be returned as soon as the timeout is reached, without waiting for the processor to stop. The
+// processors that can't be stopped (e.g yq in the middle of a document evaluation) keep running in the
+// background until they finish.
+func NewTimeoutProcessor(p Processor, timeout time.Duration) Processor {
+	if timeout <= 0 {
+		return p
+	}
+
+	return ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+		return runWithTimeout(ctx, timeout, func(ctx context.Context) (string, error) {
+			return p.Process(ctx, inputData)
+		})
+	})
+}
+
+// NewTimeoutMultiResultProcessor is like NewTimeoutProcessor but for MultiResultProcessors.
+func NewTimeoutMultiResultProcessor(p MultiResultProcessor, timeout time.Duration) MultiResultProcessor {
+	if timeout <= 0 {
+		return p
+	}
+
+	return MultiResultProcessorFunc(func(ctx context.Context, inputData string) (Results, error) {
+		return runWithTimeout(ctx, timeout, func(ctx context.Context) (Results, error) {
+			return p.ProcessResults(ctx, inputData)
+		})
+	})
+}
+
+// NewTimeoutValueProcessor is like NewTimeoutProcessor but for ValueProcessors.
+func NewTimeoutValueProcessor(p ValueProcessor, timeout time.Duration) ValueProcessor {
+	if timeout <= 0 {
+		return p
+	}
+
+	return ValueProcessorFunc(func(ctx context.Context, input any) (Results, error) {
+		return runWithTimeout(ctx, timeout, func(ctx context.Context) (Results, error) {
+			return p.ProcessValue(ctx, input)
+		})
+	})
+}
+
+// runWithTimeout executes f with a context that has the timeout as deadline, the result will be
+// returned as soon as the timeout is reached, without waiting for f to stop.
+//
+// f is not killed, if it doesn't check the context (e.g yq while it evaluates a document) its goroutine is
+// abandoned and keeps running, using CPU and memory, until it finishes.
+func runWithTimeout[T any](ctx context.Context, timeout time.Duration, f func(ctx context.Context) (T, error)) (T, error) {
+	processCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type processResult struct {
+		result T
+		err    error
+	}
+
+	var zero T
+	resC := make(chan processResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				resC <- processResult{err: fmt.Errorf("processor panicked: %v", r)}
+			}
+		}()
+
+		result, err := f(processCtx)
+		resC <- processResult{result: result, err: err}
+	}()
+
+	select {
+	case res := <-resC:
+		if res.err != nil && isTimeout(ctx, processCtx) {
+			return zero, TimeoutError{Timeout: timeout}
+		}
+		return res.result, res.err
+	case <-processCtx.Done():
+		if isTimeout(ctx, processCtx) {
+			return zero, TimeoutError{Timeout: timeout}
+		}
+		return zero, processCtx.Err()
+	}
+}
+
+// isTimeout returns true if the processor context deadline has been reached and not by the parent context.
+func isTimeout(parentCtx, processCtx context.Context) bool {
+	return parentCtx.Err() == nil && errors.Is(processCtx.Err(), context.DeadlineExceeded)
+}
@@ -0,0 +1,175 @@
+package process_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+)
+
+func TestTimeoutProcessorProcess(t *testing.T) {
+	tests := map[string]struct {
+		processor process.Processor
+		timeout   time.Duration
+		expResult string
+		expErr    error
+	}{
+		"A processor that finishes before the timeout should return its result.": {
+			processor: process.ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+				return inputData + "-processed", nil
+			}),
+			timeout:   time.Second,
+			expResult: "test-processed",
+		},
+
+		"A processor that finishes before the timeout should return its error.": {
+			processor: process.ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+				return "", fmt.Errorf("something")
+			}),
+			timeout: time.Second,
+			expErr:  fmt.Errorf("something"),
+		},
+
+		"A processor that doesn't finish before the timeout should return a timeout error.": {
+			processor: process.ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			}),
+			timeout: 10 * time.Millisecond,
+			expErr:  process.TimeoutError{Timeout: 10 * time.Millisecond},
+		},
+
+		"A processor that ignores the context should return a timeout error.": {
+			processor: process.ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+				time.Sleep(time.Second)
+				return "", nil
+			}),
+			timeout: 10 * time.Millisecond,
+			expErr:  process.TimeoutError{Timeout: 10 * time.Millisecond},
+		},
+
+		"A processor that panics should return an error.": {
+			processor: process.ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+				panic("something")
+			}),
+			timeout: time.Second,
+			expErr:  fmt.Errorf("processor panicked: something"),
+		},
+
+		"A missing timeout should not wrap the processor.": {
+			processor: process.ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+				return inputData + "-processed", nil
+			}),
+			expResult: "test-processed",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p := process.NewTimeoutProcessor(test.processor, test.timeout)
+			gotRes, err := p.Process(context.TODO(), "test")
+
+			if test.expErr != nil {
+				assert.Equal(test.expErr, err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expResult, gotRes)
+			}
+		})
+	}
+}
+
+func TestTimeoutMultiResultProcessorProcessResults(t *testing.T) {
+	tests := map[string]struct {
+		processor  process.MultiResultProcessor
+		timeout    time.Duration
+		expResults process.Results
+		expErr     error
+	}{
+		"A processor that finishes before the timeout should return its results.": {
+			processor: process.MultiResultProcessorFunc(func(ctx context.Context, inputData string) (process.Results, error) {
+				return process.Results{
+					Result:  inputData + "-1\n" + inputData + "-2",
+					Results: []string{inputData + "-1", inputData + "-2"},
+					Values:  []any{inputData + "-1", inputData + "-2"},
+				}, nil
+			}),
+			timeout: time.Second,
+			expResults: process.Results{
+				Result:  "test-1\ntest-2",
+				Results: []string{"test-1", "test-2"},
+				Values:  []any{"test-1", "test-2"},
+			},
+		},
+
+		"A processor that doesn't finish before the timeout should return a timeout error.": {
+			processor: process.MultiResultProcessorFunc(func(ctx context.Context, inputData string) (process.Results, error) {
+				<-ctx.Done()
+				return process.Results{}, ctx.Err()
+			}),
+			timeout: 10 * time.Millisecond,
+			expErr:  process.TimeoutError{Timeout: 10 * time.Millisecond},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p := process.NewTimeoutMultiResultProcessor(test.processor, test.timeout)
+			gotRes, err := p.ProcessResults(context.TODO(), "test")
+
+			if test.expErr != nil {
+				assert.Equal(test.expErr, err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expResults, gotRes)
+			}
+		})
+	}
+}
+
+func TestTimeoutValueProcessorProcessValue(t *testing.T) {
+	tests := map[string]struct {
+		processor  process.ValueProcessor
+		timeout    time.Duration
+		expResults process.Results
+		expErr     error
+	}{
+		"A processor that finishes before the timeout should return its results.": {
+			processor: process.ValueProcessorFunc(func(ctx context.Context, input any) (process.Results, error) {
+				return process.Results{Result: "test", Results: []string{"test"}, Values: []any{input}}, nil
+			}),
+			timeout:    time.Second,
+			expResults: process.Results{Result: "test", Results: []string{"test"}, Values: []any{"test"}},
+		},
+
+		"A processor that doesn't finish before the timeout should return a timeout error.": {
+			processor: process.ValueProcessorFunc(func(ctx context.Context, input any) (process.Results, error) {
+				<-ctx.Done()
+				return process.Results{}, ctx.Err()
+			}),
+			timeout: 10 * time.Millisecond,
+			expErr:  process.TimeoutError{Timeout: 10 * time.Millisecond},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			p := process.NewTimeoutValueProcessor(test.processor, test.timeout)
+			gotRes, err := p.ProcessValue(context.TODO(), "test")
+
+			if test.expErr != nil {
+				assert.Equal(test.expErr, err)
+			} else if assert.NoError(err) {
+				assert.Equal(test.expResults, gotRes)
+			}
+		})
+	}
+}
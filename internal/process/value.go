@@ -0,0 +1,62 @@
+package process
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// normalizeValue converts decoded values (e.g YAML, TOML) into the same data model as decoded JSON, that
+// is the one that JQ understands. If a value can't be converted, an error value is returned.
+func normalizeValue(name string, v any) any {
+	switch v := v.(type) {
+	case nil, bool, int, float64, string:
+		return v
+	case int64:
+		if v < math.MinInt || v > math.MaxInt {
+			return float64(v)
+		}
+		return int(v)
+	case uint64:
+		if v > math.MaxInt {
+			return float64(v)
+		}
+		return int(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		m := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			nv := normalizeValue(name, iter.Value().Interface())
+			if err, ok := nv.(error); ok {
+				return err
+			}
+			m[fmt.Sprint(iter.Key().Interface())] = nv
+		}
+		return m
+
+	case reflect.Slice, reflect.Array:
+		l := make([]any, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			nv := normalizeValue(name, rv.Index(i).Interface())
+			if err, ok := nv.(error); ok {
+				return err
+			}
+			l = append(l, nv)
+		}
+		return l
+
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	}
+
+	return fmt.Errorf("%s: unsupported value type %T", name, v)
+}
@@ -1,13 +1,20 @@
 package process
 
 import (
+	"bufio"
+	"bytes"
+	"container/list"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/mikefarah/yq/v4/pkg/yqlib"
 	"gopkg.in/op/go-logging.v1"
+	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -19,23 +26,411 @@ var (
 		yqlib.GetLogger().SetBackend(discardBackend)
 		return true
 	}()
+
+	// yq expression parser is a global that is lazy initialized without synchronization.
+	yqInitExpressionParser sync.Once
+
+	yqVarNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 )
 
-func NewYQProcessor(ctx context.Context, yqExpression string) (Processor, error) {
-	return ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
-		// Create yq instances per execution, we don't share them to avoid problems related with concurrency execution by Terraform.
-		yqEncoder := yqlib.NewYamlEncoder(2, false, false, true)
-		yqDecoder := yqlib.NewYamlDecoder()
-		yqEval := yqlib.NewStringEvaluator()
+// YQFormat is a data format supported by the yq processor.
+type YQFormat string
+
+const (
+	YQFormatYAML       YQFormat = "yaml"
+	YQFormatJSON       YQFormat = "json"
+	YQFormatXML        YQFormat = "xml"
+	YQFormatProperties YQFormat = "props"
+	YQFormatCSV        YQFormat = "csv"
+	YQFormatTSV        YQFormat = "tsv"
+)
+
+// YQFormats are all the supported yq formats, for input and output.
+var YQFormats = []YQFormat{YQFormatYAML, YQFormatJSON, YQFormatXML, YQFormatProperties, YQFormatCSV, YQFormatTSV}
+
+// YQStyle is the YAML style that will be applied to all the result nodes.
+type YQStyle string
+
+const (
+	YQStyleTagged  YQStyle = "tagged"
+	YQStyleDouble  YQStyle = "double"
+	YQStyleSingle  YQStyle = "single"
+	YQStyleLiteral YQStyle = "literal"
+	YQStyleFolded  YQStyle = "folded"
+	YQStyleFlow    YQStyle = "flow"
+)
+
+// YQStyles are all the supported yq YAML styles.
+var YQStyles = []YQStyle{YQStyleTagged, YQStyleDouble, YQStyleSingle, YQStyleLiteral, YQStyleFolded, YQStyleFlow}
+
+var yqYAMLStyles = map[YQStyle]yaml.Style{
+	YQStyleTagged:  yaml.TaggedStyle,
+	YQStyleDouble:  yaml.DoubleQuotedStyle,
+	YQStyleSingle:  yaml.SingleQuotedStyle,
+	YQStyleLiteral: yaml.LiteralStyle,
+	YQStyleFolded:  yaml.FoldedStyle,
+	YQStyleFlow:    yaml.FlowStyle,
+}
+
+// YQOptions are the options of the yq processor, the zero value uses the same defaults as yq CLI.
+type YQOptions struct {
+	// InputFormat is the format of the input data, by default YAML.
+	InputFormat YQFormat
+	// OutputFormat is the format of the result, by default YAML.
+	OutputFormat YQFormat
+	// Indent is the indentation of YAML, JSON and XML results, by default 2.
+	Indent int
+	// WrapScalar will keep the quotes and tags of YAML and properties scalar results.
+	WrapScalar bool
+	// PrintDocumentSeparators will print the separators between YAML result documents.
+	PrintDocumentSeparators bool
+	// Style is the style applied to all the YAML result nodes, by default the input style is kept.
+	Style YQStyle
+}
+
+func (y YQOptions) defaults() YQOptions {
+	if y.InputFormat == "" {
+		y.InputFormat = YQFormatYAML
+	}
+
+	if y.OutputFormat == "" {
+		y.OutputFormat = YQFormatYAML
+	}
+
+	if y.Indent == 0 {
+		y.Indent = 2
+	}
+
+	return y
+}
+
+// Validate checks the options are valid and can be used together.
+func (y YQOptions) Validate() error {
+	indentSet, y := y.Indent != 0, y.defaults()
 
-		result, err := yqEval.Evaluate(yqExpression, inputData, yqEncoder, true, yqDecoder)
+	if _, err := newYQDecoder(y.InputFormat); err != nil {
+		return err
+	}
+
+	if _, err := newYQEncoder(y); err != nil {
+		return err
+	}
+
+	// XML and properties are always decoded as a map, CSV and TSV can only encode lists.
+	isTabularOutput := y.OutputFormat == YQFormatCSV || y.OutputFormat == YQFormatTSV
+	isMapInput := y.InputFormat == YQFormatXML || y.InputFormat == YQFormatProperties
+	if isTabularOutput && isMapInput {
+		return fmt.Errorf("%q input can't be encoded as %q, %q output requires a list", y.InputFormat, y.OutputFormat, y.OutputFormat)
+	}
+
+	isYAMLOutput := y.OutputFormat == YQFormatYAML
+	switch {
+	case y.Indent < 0 || (isYAMLOutput && y.Indent > 9):
+		return fmt.Errorf("invalid indent %d", y.Indent)
+	case indentSet && y.OutputFormat != YQFormatYAML && y.OutputFormat != YQFormatJSON && y.OutputFormat != YQFormatXML:
+		return fmt.Errorf("indent can't be used with %q output", y.OutputFormat)
+	case y.WrapScalar && y.OutputFormat != YQFormatYAML && y.OutputFormat != YQFormatProperties:
+		return fmt.Errorf("wrapped scalars can't be used with %q output", y.OutputFormat)
+	case y.PrintDocumentSeparators && !isYAMLOutput:
+		return fmt.Errorf("document separators can't be used with %q output", y.OutputFormat)
+	case y.Style != "" && !isYAMLOutput:
+		return fmt.Errorf("style can't be used with %q output", y.OutputFormat)
+	}
+
+	if _, ok := yqYAMLStyles[y.Style]; y.Style != "" && !ok {
+		return fmt.Errorf("unsupported yq style %q", y.Style)
+	}
+
+	return nil
+}
+
+// NewYQProcessor returns a processor that executes the yq expression, the vars are exposed to the expression
+// as yq variables (e.g `$name`). The input data is decoded and the result is encoded using the options formats.
+func NewYQProcessor(ctx context.Context, yqExpression string, vars map[string]string, opts YQOptions) (MultiResultProcessor, error) {
+	run, err := newYQRunner(yqExpression, vars, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	inputFormat := opts.defaults().InputFormat
+	return MultiResultProcessorFunc(func(ctx context.Context, inputData string) (Results, error) {
+		yqDecoder, err := newYQDecoder(inputFormat)
 		if err != nil {
-			return "", fmt.Errorf("yq could not evaluate expression: %w", err)
+			return Results{}, err
 		}
 
-		yqlib.NewStreamEvaluator()
+		return run(ctx, inputData, yqDecoder)
+	}), nil
+}
 
-		result = strings.TrimSpace(result)
-		return result, nil
+// NewYQValueProcessor is like NewYQProcessor but the input is an already decoded value, that is used as
+// the single input document, so the input format is ignored.
+func NewYQValueProcessor(ctx context.Context, yqExpression string, vars map[string]string, opts YQOptions) (ValueProcessor, error) {
+	run, err := newYQRunner(yqExpression, vars, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ValueProcessorFunc(func(ctx context.Context, input any) (Results, error) {
+		return run(ctx, "", &yqValueDecoder{value: input})
 	}), nil
 }
+
+// yqRunner evaluates the yq expression on the input documents decoded by the decoder.
+type yqRunner func(ctx context.Context, inputData string, decoder yqlib.Decoder) (Results, error)
+
+func newYQRunner(yqExpression string, vars map[string]string, opts YQOptions) (yqRunner, error) {
+	err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+	opts = opts.defaults()
+
+	yqInitExpressionParser.Do(yqlib.InitExpressionParser)
+	expression, err := yqlib.ExpressionParser.ParseExpression(yqExpression)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse yq expression: %w", positionedSourceError(yqExpression, err))
+	}
+
+	for k := range vars {
+		if !yqVarNameRegexp.MatchString(k) {
+			return nil, fmt.Errorf("invalid yq variable name %q", k)
+		}
+	}
+
+	return func(ctx context.Context, inputData string, yqDecoder yqlib.Decoder) (Results, error) {
+		// yq doesn't support cancellation, the evaluation is stopped between the input documents and results
+		// when the context is done, but the evaluation of a document will continue until it finishes.
+		// Create yq instances per execution, we don't share them to avoid problems related with concurrency execution by Terraform.
+		yqEncoder, err := newYQEncoder(opts)
+		if err != nil {
+			return Results{}, err
+		}
+		// Every result is printed on its own, so they don't need document separators.
+		resultsOpts := opts
+		resultsOpts.PrintDocumentSeparators = false
+		yqResultsEncoder, err := newYQEncoder(resultsOpts)
+		if err != nil {
+			return Results{}, err
+		}
+
+		res, err := evaluateYQ(ctx, expression, inputData, vars, yqYAMLStyles[opts.Style], yqEncoder, yqResultsEncoder, yqDecoder)
+		if err != nil {
+			return Results{}, fmt.Errorf("yq could not evaluate expression: %w", err)
+		}
+
+		res.Result = strings.TrimSpace(res.Result)
+		return res, nil
+	}, nil
+}
+
+// newYQDecoder returns a decoder of the format, using the same settings as yq CLI defaults.
+func newYQDecoder(format YQFormat) (yqlib.Decoder, error) {
+	switch format {
+	case YQFormatYAML:
+		return yqlib.NewYamlDecoder(), nil
+	case YQFormatJSON:
+		return yqlib.NewJSONDecoder(), nil
+	case YQFormatXML:
+		return yqlib.NewXMLDecoder("+", "+content", false, true, true), nil
+	case YQFormatProperties:
+		return yqlib.NewPropertiesDecoder(), nil
+	case YQFormatCSV:
+		return yqlib.NewCSVObjectDecoder(','), nil
+	case YQFormatTSV:
+		return yqlib.NewCSVObjectDecoder('\t'), nil
+	}
+
+	return nil, fmt.Errorf("unsupported yq input format %q", format)
+}
+
+// yqValueDecoder is a yq decoder that returns an already decoded value as the only document.
+type yqValueDecoder struct {
+	value any
+	done  bool
+}
+
+func (y *yqValueDecoder) Init(reader io.Reader) {}
+
+func (y *yqValueDecoder) Decode(node *yaml.Node) error {
+	if y.done {
+		return io.EOF
+	}
+	y.done = true
+
+	var value yaml.Node
+	err := value.Encode(y.value)
+	if err != nil {
+		return fmt.Errorf("could not encode input value: %w", err)
+	}
+
+	*node = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&value}}
+	return nil
+}
+
+// newYQEncoder returns an encoder of the output format, colors are always disabled.
+func newYQEncoder(opts YQOptions) (yqlib.Encoder, error) {
+	switch opts.OutputFormat {
+	case YQFormatYAML:
+		return yqlib.NewYamlEncoder(opts.Indent, false, opts.PrintDocumentSeparators, !opts.WrapScalar), nil
+	case YQFormatJSON:
+		return yqlib.NewJSONEncoder(opts.Indent, false), nil
+	case YQFormatXML:
+		return yqlib.NewXMLEncoder(opts.Indent, "+", "+content"), nil
+	case YQFormatProperties:
+		return yqlib.NewPropertiesEncoder(!opts.WrapScalar), nil
+	case YQFormatCSV:
+		return yqlib.NewCsvEncoder(','), nil
+	case YQFormatTSV:
+		return yqlib.NewCsvEncoder('\t'), nil
+	}
+
+	return nil, fmt.Errorf("unsupported yq output format %q", opts.OutputFormat)
+}
+
+// evaluateYQ evaluates the expression on every input document, it's the same as yq string evaluator
+// but setting the variables on the evaluation context, so they never end up being part of the expression.
+// If the style is not empty, it will be applied to all the result nodes.
+//
+// Apart from the printed result, every result node is printed independently with the results encoder
+// and decoded.
+//
+// The evaluation stops with the context error before evaluating every input document and printing every result.
+func evaluateYQ(ctx context.Context, expression *yqlib.ExpressionNode, inputData string, vars map[string]string, style yaml.Style, encoder, resultsEncoder yqlib.Encoder, decoder yqlib.Decoder) (Results, error) {
+	out := new(bytes.Buffer)
+	printer := yqlib.NewPrinter(encoder, yqlib.NewSinglePrinterWriter(out))
+	treeNavigator := yqlib.NewDataTreeNavigator()
+	results := []string{}
+	values := []any{}
+
+	reader, leadingContent, err := yqReadLeadingContent(bufio.NewReader(strings.NewReader(inputData)))
+	if err != nil {
+		return Results{}, err
+	}
+
+	decoder.Init(reader)
+	for i := uint(0); ; i++ {
+		if err := ctx.Err(); err != nil {
+			return Results{}, err
+		}
+
+		var dataBucket yaml.Node
+		err := decoder.Decode(&dataBucket)
+		if errors.Is(err, io.EOF) {
+			return Results{Result: out.String(), Results: results, Values: values}, nil
+		}
+		if err != nil {
+			return Results{}, fmt.Errorf("bad input: %w", err)
+		}
+
+		// Move document comments into candidate node, otherwise unwrap drops them.
+		candidateNode := &yqlib.CandidateNode{
+			Document:        i,
+			Node:            &dataBucket,
+			TrailingContent: dataBucket.FootComment,
+		}
+		dataBucket.FootComment = ""
+		if i == 0 {
+			candidateNode.LeadingContent = leadingContent
+		}
+
+		inputList := list.New()
+		inputList.PushBack(candidateNode)
+		yqCtx := yqlib.Context{MatchingNodes: inputList}
+		for k, v := range vars {
+			// New nodes every time, expressions could mutate them.
+			value := list.New()
+			value.PushBack(&yqlib.CandidateNode{Node: &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}})
+			yqCtx.SetVariable(k, value)
+		}
+
+		result, err := treeNavigator.GetMatchingNodes(yqCtx, expression)
+		if err != nil {
+			return Results{}, err
+		}
+
+		if style != 0 {
+			for e := result.MatchingNodes.Front(); e != nil; e = e.Next() {
+				setYAMLStyle(e.Value.(*yqlib.CandidateNode).Node, style)
+			}
+		}
+
+		for e := result.MatchingNodes.Front(); e != nil; e = e.Next() {
+			if err := ctx.Err(); err != nil {
+				return Results{}, err
+			}
+
+			var b bytes.Buffer
+			node := list.New()
+			node.PushBack(e.Value)
+			err := yqlib.NewPrinter(resultsEncoder, yqlib.NewSinglePrinterWriter(&b)).PrintResults(node)
+			if err != nil {
+				return Results{}, err
+			}
+			results = append(results, strings.TrimSpace(b.String()))
+
+			var v any
+			err = e.Value.(*yqlib.CandidateNode).Node.Decode(&v)
+			if err != nil {
+				return Results{}, fmt.Errorf("could not decode result: %w", err)
+			}
+			v = normalizeValue("yq", v)
+			if err, ok := v.(error); ok {
+				return Results{}, err
+			}
+			values = append(values, v)
+		}
+
+		err = printer.PrintResults(result.MatchingNodes)
+		if err != nil {
+			return Results{}, err
+		}
+	}
+}
+
+// setYAMLStyle sets the style on the node and all its children, the same as yq `... style="x"`.
+func setYAMLStyle(node *yaml.Node, style yaml.Style) {
+	if node.Kind != yaml.DocumentNode {
+		node.Style = style
+	}
+
+	for _, n := range node.Content {
+		setYAMLStyle(n, style)
+	}
+}
+
+var yqCommentLineRegexp = regexp.MustCompile(`^\s*#`)
+
+// yqReadLeadingContent reads the leading comments and document separators of the input, so yq can keep them
+// on the result (yq does the same, but it's not exposed by the library).
+func yqReadLeadingContent(reader *bufio.Reader) (io.Reader, string, error) {
+	var sb strings.Builder
+	for {
+		peekBytes, err := reader.Peek(3)
+		if errors.Is(err, io.EOF) {
+			return reader, sb.String(), nil
+		}
+		if err != nil {
+			return reader, sb.String(), err
+		}
+
+		var line string
+		switch {
+		case string(peekBytes) == "---":
+			_, err = reader.ReadString('\n')
+			line = "$yqDocSeperator$\n"
+		case yqCommentLineRegexp.Match(peekBytes):
+			line, err = reader.ReadString('\n')
+		default:
+			return reader, sb.String(), nil
+		}
+
+		sb.WriteString(line)
+		if errors.Is(err, io.EOF) {
+			return reader, sb.String(), nil
+		}
+		if err != nil {
+			return reader, sb.String(), err
+		}
+	}
+}
@@ -2,7 +2,9 @@ package process_test
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,7 +16,10 @@ func TestYQPorcessorProcess(t *testing.T) {
 	tests := map[string]struct {
 		yqExpression string
 		inputData    string
+		vars         map[string]string
+		opts         process.YQOptions
 		expResult    string
+		expLoadErr   bool
 		expErr       bool
 	}{
 		"Simple YQ expression should be executed.": {
@@ -26,7 +31,289 @@ func TestYQPorcessorProcess(t *testing.T) {
 		"Invalid YQ expression should fail.": {
 			yqExpression: `23y2198321yasdas??"?·"!·`,
 			inputData:    "a: 12345",
-			expErr:       true,
+			expLoadErr:   true,
+		},
+
+		"A YQ expression with variables should be executed correctly.": {
+			yqExpression: `.a = $x | .b = $y`,
+			inputData:    "a: 12345",
+			vars:         map[string]string{"x": "something", "y": "other\" | thing"},
+			expResult: `a: something
+b: other" | thing`,
+		},
+
+		"Variables should be strings.": {
+			yqExpression: `.a = $x | .a | tag`,
+			inputData:    "a: 12345",
+			vars:         map[string]string{"x": "42"},
+			expResult:    "!!str",
+		},
+
+		"Missing variables should not match anything.": {
+			yqExpression: `.a = $x`,
+			inputData:    "a: 12345",
+			expResult:    "a: 12345",
+		},
+
+		"Invalid variable names should fail.": {
+			yqExpression: `.a`,
+			inputData:    "a: 12345",
+			vars:         map[string]string{"x-y": "something"},
+			expLoadErr:   true,
+		},
+
+		"Leading comments should be kept on multiple documents.": {
+			yqExpression: `.a = $x`,
+			inputData: `# Comment.
+---
+a: 1
+---
+a: 2
+`,
+			vars: map[string]string{"x": "z"},
+			expResult: `# Comment.
+a: z
+a: z`,
+		},
+
+		"XML input should be decoded.": {
+			yqExpression: `.config.server`,
+			inputData:    `<config><server port="8080">localhost</server></config>`,
+			opts:         process.YQOptions{InputFormat: process.YQFormatXML},
+			expResult: `+content: localhost
++port: "8080"`,
+		},
+
+		"JSON output should be encoded.": {
+			yqExpression: `.a`,
+			inputData:    "a: {b: [1, 2]}",
+			opts:         process.YQOptions{OutputFormat: process.YQFormatJSON},
+			expResult: `{
+  "b": [
+    1,
+    2
+  ]
+}`,
+		},
+
+		"XML input and JSON output should be converted.": {
+			yqExpression: `.`,
+			inputData:    `<config><name>test</name></config>`,
+			opts:         process.YQOptions{InputFormat: process.YQFormatXML, OutputFormat: process.YQFormatJSON},
+			expResult: `{
+  "config": {
+    "name": "test"
+  }
+}`,
+		},
+
+		"JSON input should be decoded.": {
+			yqExpression: `.a`,
+			inputData:    `{"a": "b"}`,
+			opts:         process.YQOptions{InputFormat: process.YQFormatJSON},
+			expResult:    "b",
+		},
+
+		"CSV input and TSV output should be converted.": {
+			yqExpression: `.[] |= (.age = "0")`,
+			inputData: `name,age
+john,43
+joe,10`,
+			opts:      process.YQOptions{InputFormat: process.YQFormatCSV, OutputFormat: process.YQFormatTSV},
+			expResult: "name\tage\njohn\t0\njoe\t0",
+		},
+
+		"Properties input and output should be converted.": {
+			yqExpression: `.a.b = "z"`,
+			inputData:    "a.b = c\na.d = e",
+			opts:         process.YQOptions{InputFormat: process.YQFormatProperties, OutputFormat: process.YQFormatProperties},
+			expResult:    "a.b = z\na.d = e",
+		},
+
+		"XML input and CSV output should fail.": {
+			yqExpression: `.`,
+			inputData:    `<a>b</a>`,
+			opts:         process.YQOptions{InputFormat: process.YQFormatXML, OutputFormat: process.YQFormatCSV},
+			expLoadErr:   true,
+		},
+
+		"Unknown input format should fail.": {
+			yqExpression: `.`,
+			inputData:    "a = 1",
+			opts:         process.YQOptions{InputFormat: "toml"},
+			expLoadErr:   true,
+		},
+
+		"Unknown output format should fail.": {
+			yqExpression: `.`,
+			inputData:    "a: 1",
+			opts:         process.YQOptions{OutputFormat: "toml"},
+			expLoadErr:   true,
+		},
+
+		"Indent should be used on YAML output.": {
+			yqExpression: `.`,
+			inputData:    "a:\n  b:\n  - 1\n  - 2",
+			opts:         process.YQOptions{Indent: 4},
+			expResult: `a:
+    b:
+        - 1
+        - 2`,
+		},
+
+		"Indent should be used on JSON output.": {
+			yqExpression: `.`,
+			inputData:    "a: [1]",
+			opts:         process.YQOptions{OutputFormat: process.YQFormatJSON, Indent: 4},
+			expResult: `{
+    "a": [
+        1
+    ]
+}`,
+		},
+
+		"Indent should be used on XML output.": {
+			yqExpression: `.`,
+			inputData:    "a: {b: c}",
+			opts:         process.YQOptions{OutputFormat: process.YQFormatXML, Indent: 4},
+			expResult: `<a>
+    <b>c</b>
+</a>`,
+		},
+
+		"Scalars should be unwrapped by default.": {
+			yqExpression: `.a`,
+			inputData:    `a: "b"`,
+			expResult:    "b",
+		},
+
+		"Scalars should be kept wrapped.": {
+			yqExpression: `.a`,
+			inputData:    `a: "b"`,
+			opts:         process.YQOptions{WrapScalar: true},
+			expResult:    `"b"`,
+		},
+
+		"Scalars should be kept wrapped on properties output.": {
+			yqExpression: `.`,
+			inputData:    `a: "b c"`,
+			opts:         process.YQOptions{OutputFormat: process.YQFormatProperties, WrapScalar: true},
+			expResult:    `a = "b c"`,
+		},
+
+		"Document separators should be printed.": {
+			yqExpression: `.a`,
+			inputData: `a: 1
+---
+a: 2`,
+			opts: process.YQOptions{PrintDocumentSeparators: true},
+			expResult: `1
+---
+2`,
+		},
+
+		"Tagged style should be applied to all the nodes.": {
+			yqExpression: `.`,
+			inputData:    "a: [1, b]",
+			opts:         process.YQOptions{Style: process.YQStyleTagged},
+			expResult: `!!map
+!!str a: !!seq
+  - !!int 1
+  - !!str b`,
+		},
+
+		"Double style should be applied to all the nodes.": {
+			yqExpression: `.`,
+			inputData:    "a: [1, b]",
+			opts:         process.YQOptions{Style: process.YQStyleDouble},
+			expResult: `"a":
+  - "1"
+  - "b"`,
+		},
+
+		"Single style should be applied to all the nodes.": {
+			yqExpression: `.`,
+			inputData:    "a: [1, b]",
+			opts:         process.YQOptions{Style: process.YQStyleSingle},
+			expResult: `'a':
+  - '1'
+  - 'b'`,
+		},
+
+		"Literal style should be applied to all the nodes.": {
+			yqExpression: `.`,
+			inputData:    "a: b",
+			opts:         process.YQOptions{Style: process.YQStyleLiteral},
+			expResult: `"a": |-
+  b`,
+		},
+
+		"Folded style should be applied to all the nodes.": {
+			yqExpression: `.`,
+			inputData:    "a: b",
+			opts:         process.YQOptions{Style: process.YQStyleFolded},
+			expResult: `"a": >-
+  b`,
+		},
+
+		"Flow style should be applied to all the nodes.": {
+			yqExpression: `.`,
+			inputData:    "a: [1, b]\nc: {d: e}",
+			opts:         process.YQOptions{Style: process.YQStyleFlow},
+			expResult:    "{a: [1, b], c: {d: e}}",
+		},
+
+		"Style, indent, wrapped scalars and document separators should be used together.": {
+			yqExpression: `.a`,
+			inputData: `a: {b: c}
+---
+a: d`,
+			opts: process.YQOptions{Style: process.YQStyleDouble, Indent: 4, WrapScalar: true, PrintDocumentSeparators: true},
+			expResult: `"b": "c"
+---
+"d"`,
+		},
+
+		"Style on non YAML output should fail.": {
+			yqExpression: `.`,
+			inputData:    "a: b",
+			opts:         process.YQOptions{OutputFormat: process.YQFormatJSON, Style: process.YQStyleFlow},
+			expLoadErr:   true,
+		},
+
+		"Document separators on non YAML output should fail.": {
+			yqExpression: `.`,
+			inputData:    "a: b",
+			opts:         process.YQOptions{OutputFormat: process.YQFormatJSON, PrintDocumentSeparators: true},
+			expLoadErr:   true,
+		},
+
+		"Wrapped scalars on JSON output should fail.": {
+			yqExpression: `.`,
+			inputData:    "a: b",
+			opts:         process.YQOptions{OutputFormat: process.YQFormatJSON, WrapScalar: true},
+			expLoadErr:   true,
+		},
+
+		"Indent on CSV output should fail.": {
+			yqExpression: `.`,
+			inputData:    "[{a: b}]",
+			opts:         process.YQOptions{OutputFormat: process.YQFormatCSV, Indent: 4},
+			expLoadErr:   true,
+		},
+
+		"Invalid YAML indent should fail.": {
+			yqExpression: `.`,
+			inputData:    "a: b",
+			opts:         process.YQOptions{Indent: 10},
+			expLoadErr:   true,
+		},
+
+		"Unknown style should fail.": {
+			yqExpression: `.`,
+			inputData:    "a: b",
+			opts:         process.YQOptions{Style: "fancy"},
+			expLoadErr:   true,
 		},
 
 		"Invalid input data should fail.": {
@@ -90,7 +377,11 @@ description: "Madrid is the capital of Spain"`,
 			assert := assert.New(t)
 			require := require.New(t)
 
-			yq, err := process.NewYQProcessor(context.TODO(), test.yqExpression)
+			yq, err := process.NewYQProcessor(context.TODO(), test.yqExpression, test.vars, test.opts)
+			if test.expLoadErr {
+				assert.Error(err)
+				return
+			}
 			require.NoError(err)
 
 			gotRes, err := yq.Process(context.TODO(), test.inputData)
@@ -103,3 +394,138 @@ description: "Madrid is the capital of Spain"`,
 		})
 	}
 }
+
+func TestYQPorcessorProcessResults(t *testing.T) {
+	tests := map[string]struct {
+		yqExpression string
+		inputData    string
+		opts         process.YQOptions
+		expResult    string
+		expResults   []string
+		expValues    []any
+	}{
+		"A single result should return a single result.": {
+			yqExpression: ".a",
+			inputData:    "a:\n  b: 1",
+			expResult:    "b: 1",
+			expResults:   []string{"b: 1"},
+			expValues:    []any{map[string]any{"b": 1}},
+		},
+
+		"No results should return empty results.": {
+			yqExpression: ".[] | select(. == 2)",
+			inputData:    "[1]",
+			expResult:    "",
+			expResults:   []string{},
+			expValues:    []any{},
+		},
+
+		"Multiple results should return every result.": {
+			yqExpression: ".[]",
+			inputData:    "- 1\n- a\n- b: [2]",
+			expResult:    "1\na\nb: [2]",
+			expResults:   []string{"1", "a", "b: [2]"},
+			expValues:    []any{1, "a", map[string]any{"b": []any{2}}},
+		},
+
+		"Multiple documents should return every document without separators.": {
+			yqExpression: ".",
+			inputData:    "a: 1\n---\nb: 2\n",
+			opts:         process.YQOptions{PrintDocumentSeparators: true},
+			expResult:    "a: 1\n---\nb: 2",
+			expResults:   []string{"a: 1", "b: 2"},
+			expValues:    []any{map[string]any{"a": 1}, map[string]any{"b": 2}},
+		},
+
+		"Multiple documents with a different output format should return every document.": {
+			yqExpression: ".",
+			inputData:    "a: 1\n---\nb: 2\n",
+			opts:         process.YQOptions{OutputFormat: process.YQFormatJSON, Indent: 1},
+			expResult:    "{\n \"a\": 1\n}\n{\n \"b\": 2\n}",
+			expResults:   []string{"{\n \"a\": 1\n}", "{\n \"b\": 2\n}"},
+			expValues:    []any{map[string]any{"a": 1}, map[string]any{"b": 2}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			yq, err := process.NewYQProcessor(context.TODO(), test.yqExpression, nil, test.opts)
+			require.NoError(err)
+
+			gotRes, err := yq.ProcessResults(context.TODO(), test.inputData)
+			if assert.NoError(err) {
+				assert.Equal(test.expResult, gotRes.Result)
+				assert.Equal(test.expResults, gotRes.Results)
+				assert.Equal(test.expValues, gotRes.Values)
+			}
+		})
+	}
+}
+
+func TestYQPorcessorProcessValue(t *testing.T) {
+	tests := map[string]struct {
+		yqExpression string
+		input        any
+		opts         process.YQOptions
+		expResults   process.Results
+	}{
+		"The input value should be used as the input document.": {
+			yqExpression: ".a",
+			input:        map[string]any{"a": map[string]any{"b": []any{1, "c"}}},
+			expResults: process.Results{
+				Result:  "b:\n  - 1\n  - c",
+				Results: []string{"b:\n  - 1\n  - c"},
+				Values:  []any{map[string]any{"b": []any{1, "c"}}},
+			},
+		},
+
+		"The input format should be ignored.": {
+			yqExpression: ".",
+			input:        "a,b",
+			opts:         process.YQOptions{InputFormat: process.YQFormatCSV, OutputFormat: process.YQFormatJSON},
+			expResults: process.Results{
+				Result:  `"a,b"`,
+				Results: []string{`"a,b"`},
+				Values:  []any{"a,b"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			yq, err := process.NewYQValueProcessor(context.TODO(), test.yqExpression, nil, test.opts)
+			require.NoError(err)
+
+			gotRes, err := yq.ProcessValue(context.TODO(), test.input)
+			if assert.NoError(err) {
+				assert.Equal(test.expResults, gotRes)
+			}
+		})
+	}
+}
+
+func TestYQPorcessorProcessCancel(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// Every document takes some time to be evaluated, all the documents would take minutes.
+	expression := `.a[] as $x ireduce (.; .a[] as $y ireduce (.; .a[] as $z ireduce (.; .n = 1)))`
+	inputData := strings.Repeat("---\na: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]\n", 1000)
+	p, err := process.NewYQProcessor(context.TODO(), expression, nil, process.YQOptions{})
+	require.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+
+	// The evaluation should be stopped after the running document, otherwise this would block for minutes.
+	start := time.Now()
+	_, err = p.ProcessResults(ctx, inputData)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Less(time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,32 @@
+package attributeutils
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+type duration bool
+
+func (d duration) Description(ctx context.Context) string         { return "" }
+func (d duration) MarkdownDescription(ctx context.Context) string { return "" }
+
+func (d duration) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
+		return
+	}
+
+	dur, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(req.Path.String(), "Attribute must be a valid duration (e.g 30s, 5m, 1h): "+err.Error())
+		return
+	}
+
+	if dur <= 0 {
+		resp.Diagnostics.AddError(req.Path.String(), "Attribute duration must be greater than 0")
+	}
+}
+
+// Duration is a validator that will validate that a string is a valid positive Go duration.
+const Duration = duration(false)
@@ -0,0 +1,70 @@
+package attributeutils_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/provider/attributeutils"
+)
+
+func TestDuration(t *testing.T) {
+	tests := map[string]struct {
+		value  types.String
+		expErr bool
+	}{
+		"Valid duration shouldn't fail.": {
+			value:  types.StringValue("1m30s"),
+			expErr: false,
+		},
+
+		"Invalid duration should fail.": {
+			value:  types.StringValue("10 minutes"),
+			expErr: true,
+		},
+
+		"Zero duration should fail.": {
+			value:  types.StringValue("0s"),
+			expErr: true,
+		},
+
+		"Negative duration should fail.": {
+			value:  types.StringValue("-5s"),
+			expErr: true,
+		},
+
+		"Null values shouldn't fail.": {
+			value:  types.StringNull(),
+			expErr: false,
+		},
+
+		"Unknown values shouldn't fail.": {
+			value:  types.StringUnknown(),
+			expErr: false,
+		},
+	}
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			request := validator.StringRequest{
+				Path:        path.Root("test"),
+				ConfigValue: test.value,
+			}
+			response := &validator.StringResponse{}
+
+			attributeutils.Duration.ValidateString(context.TODO(), request, response)
+
+			if test.expErr {
+				assert.True(response.Diagnostics.HasError())
+			} else {
+				assert.False(response.Diagnostics.HasError())
+			}
+		})
+	}
+}
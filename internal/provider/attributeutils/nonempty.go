@@ -3,8 +3,7 @@ package attributeutils
 import (
 	"context"
 
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
-	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 )
 
 type nonEmptyString bool
@@ -12,20 +11,13 @@ type nonEmptyString bool
 func (n nonEmptyString) Description(ctx context.Context) string         { return "" }
 func (n nonEmptyString) MarkdownDescription(ctx context.Context) string { return "" }
 
-func (n nonEmptyString) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
-	var s types.String
-	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &s)
-	resp.Diagnostics.Append(diags...)
-	if diags.HasError() {
+func (n nonEmptyString) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
 		return
 	}
 
-	if s.Unknown || s.Null {
-		return
-	}
-
-	if s.Value == "" {
-		resp.Diagnostics.AddError(req.AttributePath.String(), "Attribute can't be empty")
+	if req.ConfigValue.ValueString() == "" {
+		resp.Diagnostics.AddError(req.Path.String(), "Attribute can't be empty")
 	}
 }
 
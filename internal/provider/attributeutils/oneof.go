@@ -0,0 +1,33 @@
+package attributeutils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+type oneOf []string
+
+func (o oneOf) Description(ctx context.Context) string         { return "" }
+func (o oneOf) MarkdownDescription(ctx context.Context) string { return "" }
+
+func (o oneOf) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
+		return
+	}
+
+	for _, v := range o {
+		if req.ConfigValue.ValueString() == v {
+			return
+		}
+	}
+
+	resp.Diagnostics.AddError(req.Path.String(), fmt.Sprintf("Attribute must be one of: %s", strings.Join(o, ", ")))
+}
+
+// OneOf is a validator that will validate that a string is one of the allowed values.
+func OneOf(values ...string) validator.String {
+	return oneOf(values)
+}
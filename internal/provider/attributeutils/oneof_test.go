@@ -0,0 +1,60 @@
+package attributeutils_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/provider/attributeutils"
+)
+
+func TestOneOf(t *testing.T) {
+	tests := map[string]struct {
+		value  types.String
+		expErr bool
+	}{
+		"A value that is on the allowed values shouldn't fail.": {
+			value:  types.StringValue("b"),
+			expErr: false,
+		},
+
+		"A value that is not on the allowed values should fail.": {
+			value:  types.StringValue("d"),
+			expErr: true,
+		},
+
+		"Null values shouldn't fail.": {
+			value:  types.StringNull(),
+			expErr: false,
+		},
+
+		"Unknown values shouldn't fail.": {
+			value:  types.StringUnknown(),
+			expErr: false,
+		},
+	}
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			request := validator.StringRequest{
+				Path:        path.Root("test"),
+				ConfigValue: test.value,
+			}
+			response := &validator.StringResponse{}
+
+			attributeutils.OneOf("a", "b", "c").ValidateString(context.TODO(), request, response)
+
+			if test.expErr {
+				assert.True(response.Diagnostics.HasError())
+			} else {
+				assert.False(response.Diagnostics.HasError())
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package attributeutils
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+type positiveInt64 bool
+
+func (p positiveInt64) Description(ctx context.Context) string         { return "" }
+func (p positiveInt64) MarkdownDescription(ctx context.Context) string { return "" }
+
+func (p positiveInt64) ValidateInt64(ctx context.Context, req validator.Int64Request, resp *validator.Int64Response) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
+		return
+	}
+
+	if req.ConfigValue.ValueInt64() <= 0 {
+		resp.Diagnostics.AddError(req.Path.String(), "Attribute must be greater than 0")
+	}
+}
+
+// PositiveInt64 is a validator that will validate that an integer is greater than 0.
+const PositiveInt64 = positiveInt64(false)
@@ -0,0 +1,65 @@
+package attributeutils_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/provider/attributeutils"
+)
+
+func TestPositiveInt64(t *testing.T) {
+	tests := map[string]struct {
+		value  types.Int64
+		expErr bool
+	}{
+		"Positive integer shouldn't fail.": {
+			value:  types.Int64Value(1024),
+			expErr: false,
+		},
+
+		"Zero should fail.": {
+			value:  types.Int64Value(0),
+			expErr: true,
+		},
+
+		"Negative integer should fail.": {
+			value:  types.Int64Value(-1),
+			expErr: true,
+		},
+
+		"Null values shouldn't fail.": {
+			value:  types.Int64Null(),
+			expErr: false,
+		},
+
+		"Unknown values shouldn't fail.": {
+			value:  types.Int64Unknown(),
+			expErr: false,
+		},
+	}
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			request := validator.Int64Request{
+				Path:        path.Root("test"),
+				ConfigValue: test.value,
+			}
+			response := &validator.Int64Response{}
+
+			attributeutils.PositiveInt64.ValidateInt64(context.TODO(), request, response)
+
+			if test.expErr {
+				assert.True(response.Diagnostics.HasError())
+			} else {
+				assert.False(response.Diagnostics.HasError())
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package attributeutils
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+type matchRegexp struct {
+	re      *regexp.Regexp
+	message string
+}
+
+func (m matchRegexp) Description(ctx context.Context) string         { return "" }
+func (m matchRegexp) MarkdownDescription(ctx context.Context) string { return "" }
+
+func (m matchRegexp) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
+		return
+	}
+
+	if !m.re.MatchString(req.ConfigValue.ValueString()) {
+		resp.Diagnostics.AddError(req.Path.String(), m.message)
+	}
+}
+
+// MatchRegexp is a validator that will validate that a string matches a regex, if it doesn't match
+// the error will have the message.
+func MatchRegexp(re *regexp.Regexp, message string) validator.String {
+	return matchRegexp{re: re, message: message}
+}
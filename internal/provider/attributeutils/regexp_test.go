@@ -0,0 +1,61 @@
+package attributeutils_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/provider/attributeutils"
+)
+
+func TestMatchRegexp(t *testing.T) {
+	tests := map[string]struct {
+		value  types.String
+		expErr bool
+	}{
+		"A value that matches the regex shouldn't fail.": {
+			value:  types.StringValue("abc123"),
+			expErr: false,
+		},
+
+		"A value that doesn't match the regex should fail.": {
+			value:  types.StringValue("abc-123"),
+			expErr: true,
+		},
+
+		"Null values shouldn't fail.": {
+			value:  types.StringNull(),
+			expErr: false,
+		},
+
+		"Unknown values shouldn't fail.": {
+			value:  types.StringUnknown(),
+			expErr: false,
+		},
+	}
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			request := validator.StringRequest{
+				Path:        path.Root("test"),
+				ConfigValue: test.value,
+			}
+			response := &validator.StringResponse{}
+
+			attributeutils.MatchRegexp(regexp.MustCompile(`^[a-z0-9]+$`), "Invalid").ValidateString(context.TODO(), request, response)
+
+			if test.expErr {
+				assert.True(response.Diagnostics.HasError())
+			} else {
+				assert.False(response.Diagnostics.HasError())
+			}
+		})
+	}
+}
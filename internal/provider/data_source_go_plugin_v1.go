@@ -2,20 +2,39 @@ package provider
 
 import (
 	"context"
-	"time"
+	"regexp"
 
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/slok/terraform-provider-dataprocessor/internal/process"
 	"github.com/slok/terraform-provider-dataprocessor/internal/provider/attributeutils"
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
 )
 
-type dataSourceGoPluginV1Type struct{}
+var (
+	_ datasource.DataSourceWithConfigure      = &dataSourceGoPluginV1{}
+	_ datasource.DataSourceWithValidateConfig = &dataSourceGoPluginV1{}
+)
+
+func newDataSourceGoPluginV1() datasource.DataSource {
+	return &dataSourceGoPluginV1{}
+}
 
-func (d dataSourceGoPluginV1Type) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
-	return tfsdk.Schema{
+type dataSourceGoPluginV1 struct {
+	p provider
+}
+
+func (d *dataSourceGoPluginV1) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_go_plugin_v1"
+}
+
+func (d *dataSourceGoPluginV1) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
 		Description: `
 Executes a Go plugin v1 processor providing the result.
 
@@ -34,55 +53,126 @@ Check [examples](https://github.com/slok/terraform-provider-dataprocessor/tree/m
 - [Complex validation](https://github.com/slok/terraform-provider-dataprocessor/tree/main/examples/plugins/complex_validation): Validate Prometheus Rules. Shows how to create advanced logic plugins.
 - [Data structure transformation](https://github.com/slok/terraform-provider-dataprocessor/tree/main/examples/plugins/data_structure_transformation/): Transforms a data structure into another. Shows how to transform data for easier consumption by different terraform providers.
 - [Filtering](https://github.com/slok/terraform-provider-dataprocessor/tree/main/examples/plugins/filtering/): Filters a list of usernames based on a regex. Shows how to filter terraform data to avoid HCL complex logic.
-- [Remote plugin](https://github.com/slok/terraform-provider-dataprocessor/tree/main/examples/plugins/remote_plugin/): Uses a plugin that is hosted in github. Shows how plugins can be shared and create plugin repos.
+- [Remote plugin](https://github.com/slok/terraform-provider-dataprocessor/tree/main/examples/plugins/remote_plugin/): Uses a plugin that is hosted in github pinned to its checksum. Shows how plugins can be shared and create plugin repos.
 - [Simple validation](https://github.com/slok/terraform-provider-dataprocessor/tree/main/examples/plugins/simple_validation/): Validates the length of a string. Shows that simple validation plugins can be powerful (like small functions), perfect to be used as a remote plugin.
 
+## Remote plugins
+
+Plugins can be loaded from an URL using ` + "`plugin_url`" + `, the plugin will only be executed if the downloaded source code matches
+the ` + "`plugin_sha256`" + ` checksum, so the executed code is always the reviewed one. The downloaded plugins are cached locally.
+
+## Plugin API
+
+Plugins can import the ` + "`github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor`" + ` package (available on all the sandboxes):
+
+- ` + "`dataprocessor.AddFinding(ctx, dataprocessor.Finding{...})`" + `: Reports a finding, every finding is shown as its own Terraform error
+  or warning (depending on its ` + "`Severity`" + `) and is available on the ` + "`findings`" + ` attribute. The error findings fail the execution,
+  so validation plugins don't need to build a single error with all the problems.
+- ` + "`dataprocessor.AddWarning(ctx, summary, detail)`" + `: Shows a Terraform warning without failing the execution.
+- ` + "`dataprocessor.Debug`" + `, ` + "`Info`" + `, ` + "`Warn`" + ` and ` + "`Error`" + `: Write on the provider logs (e.g ` + "`TF_LOG=debug`" + `) with the data source
+  fields.
+
+The plugin standard output and error (e.g ` + "`fmt.Println`" + ` or ` + "`log.Println`" + `) are captured on the ` + "`stdout`" + ` and ` + "`stderr`" + `
+attributes and written on the provider debug logs.
 `,
-		Attributes: map[string]tfsdk.Attribute{
-			"plugin": {
-				Description: "The Go plugin v1 source code. Uses the `func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error)` signature.",
-				Required:    true,
-				Type:        types.StringType,
-				Validators:  []tfsdk.AttributeValidator{attributeutils.NonEmptyString},
+		Attributes: map[string]schema.Attribute{
+			"plugin": schema.StringAttribute{
+				Description: "The Go plugin v1 source code. Uses the `func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error)` signature. Conflicts with `plugin_url`. The loaded plugins are reused between executions, the package level variables are not reset, so the plugins should not keep state on them.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.NonEmptyString},
+			},
+			"plugin_url": schema.StringAttribute{
+				Description: "The URL to download the Go plugin v1 source code from. Requires `plugin_sha256`, conflicts with `plugin`.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.NonEmptyString},
+			},
+			"plugin_sha256": schema.StringAttribute{
+				Description: "The hex encoded sha256 checksum of the Go plugin source code downloaded from `plugin_url`, the plugin will not be executed if it doesn't match.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.MatchRegexp(sha256Regexp, "Attribute must be a hex encoded sha256 checksum")},
+			},
+			"input_data": schema.StringAttribute{
+				Description: "The input raw data that will be processed by the loaded plugin. Required unless `input` is set.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.NonEmptyString},
 			},
-			"input_data": {
-				Description:   `The input raw data that will be processed by the loaded plugin.`,
-				Required:      true,
-				Type:          types.StringType,
-				Validators:    []tfsdk.AttributeValidator{attributeutils.NonEmptyString},
-				PlanModifiers: tfsdk.AttributePlanModifiers{attributeutils.DefaultValue(types.String{Value: "{}"})},
+			"input": schema.DynamicAttribute{
+				Description: "The input value that will be processed by the loaded plugin, strings are passed as they are and any other Terraform value (e.g object, list...) is encoded in JSON. Conflicts with `input_data`.",
+				Optional:    true,
 			},
-			"vars": {
+			"vars": schema.MapAttribute{
 				Description: `Variables that will be passed to the plugin execution.`,
 				Optional:    true,
-				Type:        types.MapType{ElemType: types.StringType},
+				ElementType: types.StringType,
+			},
+			"max_input_size": schema.Int64Attribute{
+				Description: "Maximum size in bytes of the input data, the `input` values are measured encoded in JSON. Defaults to the provider `max_input_size`.",
+				Optional:    true,
+				Validators:  []validator.Int64{attributeutils.PositiveInt64},
 			},
-			"result": {
+			"timeout": schema.StringAttribute{
+				Description: "Maximum duration of the processor execution (e.g `30s`, `5m`). Defaults to the provider `timeout`.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.Duration},
+			},
+			"sandbox": schema.StringAttribute{
+				Description: "The sandbox preset of the plugin (`unrestricted` or `safe`). Defaults to the provider `go_plugin_sandbox`.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.OneOf(sandboxPresets()...)},
+			},
+			"sandbox_allow": schema.ListAttribute{
+				Description: "Go standard library packages (e.g `net/http`) or symbols (e.g `os.WriteFile`) allowed on top of the sandbox preset. Defaults to the provider `go_plugin_sandbox_allow`.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"result": schema.StringAttribute{
 				Description: `Plugin execution result.`,
 				Computed:    true,
-				Type:        types.StringType,
 			},
-			"id": {
-				Description: `Not used, can be ignored.`,
+			"result_value": schema.DynamicAttribute{
+				Description: "Plugin execution result decoded as a Terraform value (e.g object, list, number...) when it's valid JSON, otherwise the result string.",
+				Computed:    true,
+			},
+			"stdout":   stdoutAttribute(),
+			"stderr":   stderrAttribute(),
+			"findings": findingsAttribute(),
+			"id": schema.StringAttribute{
+				Description: "Hash of the plugin source code, the input, the vars and the sandbox, it only changes when the result can change, so it can be used as a change signal (e.g `replace_triggered_by`).",
 				Computed:    true,
-				Type:        types.StringType,
 			},
 		},
-	}, nil
+	}
 }
 
-func (d dataSourceGoPluginV1Type) NewDataSource(ctx context.Context, p tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
-	prv := p.(*provider)
-	return dataSourceGoPluginV1{
-		p: *prv,
-	}, nil
+func (d *dataSourceGoPluginV1) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// The provider data is not set until the provider has been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.p = *req.ProviderData.(*provider)
 }
 
-type dataSourceGoPluginV1 struct {
-	p provider
+var sha256Regexp = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+func (d *dataSourceGoPluginV1) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var tfGoPluginV1 GoPluginV1
+	diags := req.Config.Get(ctx, &tfGoPluginV1)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateGoPluginV1(&resp.Diagnostics, tfGoPluginV1)
+}
+
+// validateGoPluginV1 validates the Go plugin v1 processor configuration.
+func validateGoPluginV1(diags *diag.Diagnostics, tfGoPluginV1 GoPluginV1) {
+	validateGoPluginSource(diags, tfGoPluginV1.Plugin, tfGoPluginV1.PluginURL, tfGoPluginV1.PluginSHA256)
+	validateInput(diags, tfGoPluginV1.InputData, tfGoPluginV1.Input, false)
 }
 
-func (d dataSourceGoPluginV1) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+func (d *dataSourceGoPluginV1) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	if !d.p.configured {
 		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
 		return
@@ -96,30 +186,110 @@ func (d dataSourceGoPluginV1) Read(ctx context.Context, req tfsdk.ReadDataSource
 		return
 	}
 
-	// Execute JQ.
+	d.p.processGoPluginV1(ctx, &resp.Diagnostics, &tfGoPluginV1)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, tfGoPluginV1)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// processGoPluginV1 executes the Go plugin v1 processor with the configuration and sets the results and the ID
+// on it, the errors are added to the diagnostics.
+func (p provider) processGoPluginV1(ctx context.Context, diags *diag.Diagnostics, tfGoPluginV1 *GoPluginV1) {
+	// v1 plugins only understand strings, the input values are encoded.
+	inputData := tfGoPluginV1.InputData.ValueString()
+	if !tfGoPluginV1.Input.IsNull() {
+		input, err := inputValue(ctx, tfGoPluginV1.Input)
+		if err != nil {
+			diags.AddAttributeError(path.Root("input"), "Invalid input", err.Error())
+			return
+		}
+
+		inputData, err = inputString(input)
+		if err != nil {
+			diags.AddAttributeError(path.Root("input"), "Invalid input", err.Error())
+			return
+		}
+	}
+
+	// Check input data limits.
+	err := p.checkInputSize(inputData, tfGoPluginV1.MaxInputSize)
+	if err != nil {
+		diags.AddAttributeError(inputPath(tfGoPluginV1.Input), "Invalid input data", err.Error())
+		return
+	}
+
+	timeout, err := p.processorTimeout(tfGoPluginV1.Timeout)
+	if err != nil {
+		diags.AddAttributeError(path.Root("timeout"), "Invalid timeout", err.Error())
+		return
+	}
+
+	// Get the plugin source, remote plugins are verified before loading them.
+	pluginSrc, err := p.goPluginSource(ctx, tfGoPluginV1.Plugin, tfGoPluginV1.PluginURL, tfGoPluginV1.PluginSHA256)
+	if err != nil {
+		addGoPluginSourceError(diags, err)
+		return
+	}
+
+	// Execute plugin.
 	vars := map[string]string{}
 	for k, v := range tfGoPluginV1.Vars {
-		vars[k] = v.Value
+		vars[k] = v.ValueString()
 	}
-	plugin, err := process.NewGoPluginV1Processor(ctx, tfGoPluginV1.Plugin.Value, vars)
+	sandbox := p.goPluginSandbox(tfGoPluginV1.Sandbox, tfGoPluginV1.SandboxAllow)
+	plugin, err := process.NewGoPluginV1Processor(ctx, pluginSrc, vars, sandbox)
 	if err != nil {
-		resp.Diagnostics.AddError("Error creating Go plugin v1 processor", "Could not create Go plugin v1 processor, unexpected error: "+err.Error())
+		addGoPluginLoadError(diags, "Go plugin v1", goPluginSourcePath(tfGoPluginV1.PluginURL), err)
 		return
 	}
 
-	result, err := plugin.Process(ctx, tfGoPluginV1.InputData.Value)
+	// The findings and the output are reported even if the plugin fails, they can explain the failure.
+	recorder := &dataprocessor.Recorder{}
+	output := &goPluginOutput{}
+	plugin = process.NewTimeoutProcessor(plugin, timeout)
+	result, err := plugin.Process(output.context(dataprocessor.ContextWithRecorder(ctx, recorder)), inputData)
+	output.log(ctx)
+	addFindings(diags, inputPath(tfGoPluginV1.Input), recorder.Findings())
 	if err != nil {
-		resp.Diagnostics.AddError("Error executing Go plugin v1 processor", "Could not process input data, unexpected error: "+err.Error())
+		addProcessError(diags, "Go plugin v1", err)
 		return
 	}
-	tfGoPluginV1.Result = types.String{Value: result}
+	// The error findings fail the execution.
+	if diags.HasError() {
+		return
+	}
+	tfGoPluginV1.Result = types.StringValue(result)
+	tfGoPluginV1.Stdout = types.StringValue(output.stdout.String())
+	tfGoPluginV1.Stderr = types.StringValue(output.stderr.String())
 
-	// Force execution every time.
-	tfGoPluginV1.ID = types.String{Value: time.Now().String()}
+	findings, d := findingsValue(ctx, recorder.Findings())
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+	tfGoPluginV1.Findings = findings
 
-	diags = resp.State.Set(ctx, tfGoPluginV1)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+	// The plugin results are strings, if the result is JSON we can decode it.
+	tfGoPluginV1.ResultValue = types.DynamicValue(types.StringValue(result))
+	if v, err := jsonResultValue(ctx, result); err == nil {
+		tfGoPluginV1.ResultValue = v
+	}
+
+	id, err := contentID("go_plugin_v1", map[string]any{
+		"plugin":     pluginSrc,
+		"input_data": inputData,
+		"vars":       vars,
+		"sandbox":    sandbox,
+	})
+	if err != nil {
+		diags.AddError("Error calculating ID", "Could not calculate the ID of the Go plugin v1 execution: "+err.Error())
 		return
 	}
+	tfGoPluginV1.ID = types.StringValue(id)
 }
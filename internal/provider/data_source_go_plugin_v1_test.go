@@ -1,18 +1,23 @@
 package provider_test
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 // TestAccDataSourceGoPluginV1 will check a go plugin v1 execution.
 func TestAccDataSourceGoPluginV1(t *testing.T) {
 	tests := map[string]struct {
-		config    string
-		expResult string
-		expErr    *regexp.Regexp
+		config         string
+		expResult      string
+		expResultValue map[string]string
+		expErr         *regexp.Regexp
 	}{
 		"Not having input data should fail.": {
 			config: `
@@ -51,7 +56,7 @@ func
 	EOT
 }
 		`,
-			expErr: regexp.MustCompile(`Could not create Go plugin v1 processor, unexpected error: could not load`),
+			expErr: regexp.MustCompile(`Could not create Go plugin v1 processor, could not load`),
 		},
 
 		"Simple transparent plugin should return the input transparently.": {
@@ -68,7 +73,8 @@ func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]st
 }
 	EOT
 }`,
-			expResult: `this is a test`,
+			expResult:      `this is a test`,
+			expResultValue: map[string]string{"result_value": "this is a test"},
 		},
 
 		"Variable should work in the plugin logic.": {
@@ -102,6 +108,59 @@ func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]st
 			expResult: `this is a testa=b,x=y`,
 		},
 
+		"Input values should be encoded in JSON.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input  = {a = [1, "<b>"]}
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return inputData, nil
+}
+	EOT
+}`,
+			expResult:      `{"a":[1,"<b>"]}`,
+			expResultValue: map[string]string{"result_value.a.#": "2", "result_value.a.0": "1", "result_value.a.1": "<b>"},
+		},
+
+		"String input values should be passed as they are.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input  = "this is a test"
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return inputData, nil
+}
+	EOT
+}`,
+			expResult: `this is a test`,
+		},
+
+		"A plugin returning JSON should return the result decoded.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{\"a\": [1, 2]}"
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return inputData, nil
+}
+	EOT
+}`,
+			expResult:      `{"a": [1, 2]}`,
+			expResultValue: map[string]string{"result_value.a.#": "2", "result_value.a.0": "1", "result_value.a.1": "2"},
+		},
+
 		"If the plugin fails, it should fail.": {
 			config: `
 data "dataprocessor_go_plugin_v1" "test" {
@@ -121,6 +180,378 @@ func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]st
 }`,
 			expErr: regexp.MustCompile("Could not process input data, unexpected error: error from plugin"),
 		},
+
+		"The plugin warning findings should be reported without failing.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{}"
+	plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	dataprocessor.AddFinding(ctx, dataprocessor.Finding{
+		Severity: dataprocessor.SeverityWarning,
+		Summary:  "Deprecated field",
+		Detail:   "use b instead",
+		Path:     "a",
+	})
+	return inputData, nil
+}
+	EOT
+}`,
+			expResult: `{}`,
+			expResultValue: map[string]string{
+				"findings.#":          "1",
+				"findings.0.severity": "warning",
+				"findings.0.summary":  "Deprecated field",
+				"findings.0.detail":   "use b instead",
+				"findings.0.path":     "a",
+			},
+		},
+
+		"The plugin warnings and logs should not fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{}"
+	plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	dataprocessor.Info(ctx, "processing input", map[string]any{"size": len(inputData)})
+	dataprocessor.AddWarning(ctx, "Empty input", "the input has no fields")
+	return inputData, nil
+}
+	EOT
+}`,
+			expResult: `{}`,
+			expResultValue: map[string]string{
+				"findings.#":          "1",
+				"findings.0.severity": "warning",
+				"findings.0.summary":  "Empty input",
+				"findings.0.detail":   "the input has no fields",
+			},
+		},
+
+		"The plugin output should be captured.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{}"
+	plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	fmt.Println("input:", inputData)
+	fmt.Fprint(os.Stderr, "debug")
+	return inputData, nil
+}
+	EOT
+}`,
+			expResult:      `{}`,
+			expResultValue: map[string]string{"stdout": "input: {}\n", "stderr": "debug"},
+		},
+
+		"The plugin error findings should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{}"
+	plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	dataprocessor.AddFinding(ctx, dataprocessor.Finding{Summary: "Missing field", Detail: "b is required", Path: "a"})
+	return inputData, nil
+}
+	EOT
+}`,
+			expErr: regexp.MustCompile(`(?s)Missing field.*a: b is required`),
+		},
+
+		"A plugin that takes more than the timeout should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{}"
+	timeout = "100ms"
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	for {
+	}
+}
+	EOT
+}`,
+			expErr: regexp.MustCompile(`processor timed out after 100ms`),
+		},
+
+		"A plugin that takes more than the provider timeout should fail.": {
+			config: `
+provider "dataprocessor" {
+	timeout = "100ms"
+}
+
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{}"
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	for {
+	}
+}
+	EOT
+}`,
+			expErr: regexp.MustCompile(`processor timed out after 100ms`),
+		},
+
+		"A plugin using a forbidden package by the sandbox should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{}"
+	sandbox = "safe"
+	plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+	"net/http"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return http.MethodGet, nil
+}
+	EOT
+}`,
+			expErr: regexp.MustCompile(`The plugin imports the "net/http" package, that is not allowed by the "safe"`),
+		},
+
+		"A plugin using a forbidden package by the provider sandbox should fail.": {
+			config: `
+provider "dataprocessor" {
+	go_plugin_sandbox = "safe"
+}
+
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{}"
+	plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+	"net/http"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return http.MethodGet, nil
+}
+	EOT
+}`,
+			expErr: regexp.MustCompile(`The plugin imports the "net/http" package, that is not allowed by the "safe"`),
+		},
+
+		"A plugin using a package allowed by the sandbox allow list should be executed.": {
+			config: `
+provider "dataprocessor" {
+	go_plugin_sandbox = "safe"
+}
+
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{}"
+	sandbox_allow = ["net/http"]
+	plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+	"net/http"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return http.MethodGet, nil
+}
+	EOT
+}`,
+			expResult: "GET",
+		},
+
+		"An invalid sandbox preset should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{}"
+	sandbox = "something"
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return inputData, nil
+}
+	EOT
+}`,
+			expErr: regexp.MustCompile(`Attribute must be one of: unrestricted, safe`),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Prepare non error checks.
+			var checks resource.TestCheckFunc
+			if test.expErr == nil {
+				checkFuncs := []resource.TestCheckFunc{
+					resource.TestCheckResourceAttr("data.dataprocessor_go_plugin_v1.test", "result", test.expResult),
+				}
+				for k, v := range test.expResultValue {
+					checkFuncs = append(checkFuncs, resource.TestCheckResourceAttr("data.dataprocessor_go_plugin_v1.test", k, v))
+				}
+				checks = resource.ComposeAggregateTestCheckFunc(checkFuncs...)
+			}
+
+			// Check.
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config:      test.config,
+						Check:       checks,
+						ExpectError: test.expErr,
+					},
+				},
+			})
+		})
+	}
+}
+
+const testRemotePluginV1 = `package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return "remote: " + inputData, nil
+}
+`
+
+// TestAccDataSourceGoPluginV1Remote will check a go plugin v1 execution loaded from an URL.
+func TestAccDataSourceGoPluginV1Remote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/plugin.go" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(testRemotePluginV1))
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		config    string
+		expResult string
+		expErr    *regexp.Regexp
+	}{
+		"A remote plugin matching the checksum should be executed.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data    = "this is a test"
+	plugin_url    = "{{URL}}/plugin.go"
+	plugin_sha256 = "6afcc35bbac9cbf9acfd050124be499a9e281fcc2b163e8377811c73284dfdc5"
+}`,
+			expResult: "remote: this is a test",
+		},
+
+		"A remote plugin not matching the checksum should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data    = "this is a test"
+	plugin_url    = "{{URL}}/plugin.go"
+	plugin_sha256 = "0000000000000000000000000000000000000000000000000000000000000000"
+}`,
+			expErr: regexp.MustCompile(`Go plugin checksum mismatch`),
+		},
+
+		"A missing remote plugin should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data    = "this is a test"
+	plugin_url    = "{{URL}}/missing.go"
+	plugin_sha256 = "6afcc35bbac9cbf9acfd050124be499a9e281fcc2b163e8377811c73284dfdc5"
+}`,
+			expErr: regexp.MustCompile(`unexpected status code 404`),
+		},
+
+		"An invalid checksum should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data    = "this is a test"
+	plugin_url    = "{{URL}}/plugin.go"
+	plugin_sha256 = "abc"
+}`,
+			expErr: regexp.MustCompile(`Attribute must be a hex encoded sha256 checksum`),
+		},
+
+		"A remote plugin without checksum should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "this is a test"
+	plugin_url = "{{URL}}/plugin.go"
+}`,
+			expErr: regexp.MustCompile("`plugin_sha256` is required when `plugin_url` is set"),
+		},
+
+		"A checksum without remote plugin should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data    = "this is a test"
+	plugin_sha256 = "6afcc35bbac9cbf9acfd050124be499a9e281fcc2b163e8377811c73284dfdc5"
+	plugin        = "package testplugin"
+}`,
+			expErr: regexp.MustCompile("`plugin_sha256` can only be set with `plugin_url`"),
+		},
+
+		"Setting both plugin source code and remote plugin should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data    = "this is a test"
+	plugin        = "package testplugin"
+	plugin_url    = "{{URL}}/plugin.go"
+	plugin_sha256 = "6afcc35bbac9cbf9acfd050124be499a9e281fcc2b163e8377811c73284dfdc5"
+}`,
+			expErr: regexp.MustCompile("Only one of `plugin` or `plugin_url` can be set"),
+		},
+
+		"Not setting any plugin should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v1" "test" {
+	input_data = "this is a test"
+}`,
+			expErr: regexp.MustCompile("One of `plugin` or `plugin_url` must be set"),
+		},
 	}
 
 	for name, test := range tests {
@@ -133,13 +564,20 @@ func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]st
 				)
 			}
 
+			// Use the test server and don't pollute the user cache.
+			config := fmt.Sprintf(`
+provider "dataprocessor" {
+	plugin_cache_dir = %q
+}
+%s`, t.TempDir(), strings.ReplaceAll(test.config, "{{URL}}", srv.URL))
+
 			// Check.
 			resource.Test(t, resource.TestCase{
 				PreCheck:                 func() { testAccPreCheck(t) },
 				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 				Steps: []resource.TestStep{
 					{
-						Config:      test.config,
+						Config:      config,
 						Check:       checks,
 						ExpectError: test.expErr,
 					},
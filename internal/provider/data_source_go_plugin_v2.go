@@ -0,0 +1,264 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+	"github.com/slok/terraform-provider-dataprocessor/internal/provider/attributeutils"
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+var (
+	_ datasource.DataSourceWithConfigure      = &dataSourceGoPluginV2{}
+	_ datasource.DataSourceWithValidateConfig = &dataSourceGoPluginV2{}
+)
+
+func newDataSourceGoPluginV2() datasource.DataSource {
+	return &dataSourceGoPluginV2{}
+}
+
+type dataSourceGoPluginV2 struct {
+	p provider
+}
+
+func (d *dataSourceGoPluginV2) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_go_plugin_v2"
+}
+
+func (d *dataSourceGoPluginV2) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `
+Executes a Go plugin v2 processor providing the result.
+
+Unlike v1 plugins, v2 plugins receive the JSON input data already decoded, typed variables and return
+any value that will be encoded into JSON, so plugins don't need to decode and encode the data by themselves.
+
+The requirements for a plugin are:
+
+- Written in Go.
+- No external dependencies, only Go standard library.
+- Implemented in a single file (or string block).
+- Implement the plugin API (Check the examples to know how to do it).
+  - The Filter function should be called: _ProcessorPluginV2_.
+  - The Filter function should have this signature: _ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (result any, error error)_.
+
+Like v1 plugins, v2 plugins can use the ` + "`github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor`" + ` package to report
+findings and warnings, and to write on the provider logs. Every finding is shown as its own Terraform error or warning and is
+available on the ` + "`findings`" + ` attribute.
+`,
+		Attributes: map[string]schema.Attribute{
+			"plugin": schema.StringAttribute{
+				Description: "The Go plugin v2 source code. Uses the `func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error)` signature. The loaded plugins are reused between executions, the package level variables are not reset, so the plugins should not keep state on them.",
+				Required:    true,
+				Validators:  []validator.String{attributeutils.NonEmptyString},
+			},
+			"input_data": schema.StringAttribute{
+				Description: "The input JSON data that will be decoded and processed by the loaded plugin. Required unless `input` is set.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.NonEmptyString},
+			},
+			"input": schema.DynamicAttribute{
+				Description: "The input value that will be processed by the loaded plugin, any Terraform value (e.g object, list, string...) is passed to the plugin directly without encoding it with `jsonencode`. Conflicts with `input_data`.",
+				Optional:    true,
+			},
+			"vars": schema.MapAttribute{
+				Description: `Variables that will be passed to the plugin execution as strings.`,
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"json_vars": schema.MapAttribute{
+				Description: "Variables in JSON format that will be decoded and passed to the plugin execution (e.g `jsonencode(3)` or `jsonencode([\"a\", \"b\"])`). Can't use the same names as `vars`.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"max_input_size": schema.Int64Attribute{
+				Description: "Maximum size in bytes of the input data, the `input` values are measured encoded in JSON. Defaults to the provider `max_input_size`.",
+				Optional:    true,
+				Validators:  []validator.Int64{attributeutils.PositiveInt64},
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Maximum duration of the processor execution (e.g `30s`, `5m`). Defaults to the provider `timeout`.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.Duration},
+			},
+			"sandbox": schema.StringAttribute{
+				Description: "The sandbox preset of the plugin (`unrestricted` or `safe`). Defaults to the provider `go_plugin_sandbox`.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.OneOf(sandboxPresets()...)},
+			},
+			"sandbox_allow": schema.ListAttribute{
+				Description: "Go standard library packages (e.g `net/http`) or symbols (e.g `os.WriteFile`) allowed on top of the sandbox preset. Defaults to the provider `go_plugin_sandbox_allow`.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"result": schema.StringAttribute{
+				Description: `Plugin execution result encoded in JSON.`,
+				Computed:    true,
+			},
+			"result_value": schema.DynamicAttribute{
+				Description: "Plugin execution result as a Terraform value (e.g object, list, number...), so it doesn't need `jsondecode`.",
+				Computed:    true,
+			},
+			"stdout":   stdoutAttribute(),
+			"stderr":   stderrAttribute(),
+			"findings": findingsAttribute(),
+			"id": schema.StringAttribute{
+				Description: "Hash of the plugin source code, the input, the vars and the sandbox, it only changes when the result can change, so it can be used as a change signal (e.g `replace_triggered_by`).",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *dataSourceGoPluginV2) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// The provider data is not set until the provider has been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.p = *req.ProviderData.(*provider)
+}
+
+func (d *dataSourceGoPluginV2) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var tfGoPluginV2 GoPluginV2
+	diags := req.Config.Get(ctx, &tfGoPluginV2)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateInput(&resp.Diagnostics, tfGoPluginV2.InputData, tfGoPluginV2.Input, false)
+}
+
+func (d *dataSourceGoPluginV2) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if !d.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
+	}
+
+	// Retrieve values.
+	var tfGoPluginV2 GoPluginV2
+	diags := req.Config.Get(ctx, &tfGoPluginV2)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Check input data limits.
+	err := d.p.checkInputSize(tfGoPluginV2.InputData.ValueString(), tfGoPluginV2.MaxInputSize)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("input_data"), "Invalid input data", err.Error())
+		return
+	}
+
+	timeout, err := d.p.processorTimeout(tfGoPluginV2.Timeout)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("timeout"), "Invalid timeout", err.Error())
+		return
+	}
+
+	// Load vars.
+	vars := decodeVars(&resp.Diagnostics, tfGoPluginV2.Vars, tfGoPluginV2.JSONVars)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Execute plugin, the findings and the output are reported even if the plugin fails, they can explain the failure.
+	sandbox := d.p.goPluginSandbox(tfGoPluginV2.Sandbox, tfGoPluginV2.SandboxAllow)
+	recorder := &dataprocessor.Recorder{}
+	output := &goPluginOutput{}
+	pluginCtx := output.context(dataprocessor.ContextWithRecorder(ctx, recorder))
+	var input any
+	if !tfGoPluginV2.Input.IsNull() {
+		input, err = inputValue(ctx, tfGoPluginV2.Input)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("input"), "Invalid input", err.Error())
+			return
+		}
+		err = d.p.checkInputValueSize(input, tfGoPluginV2.MaxInputSize)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("input"), "Invalid input", err.Error())
+			return
+		}
+
+		plugin, err := process.NewGoPluginV2ValueProcessor(ctx, tfGoPluginV2.Plugin.ValueString(), vars, sandbox)
+		if err != nil {
+			addGoPluginLoadError(&resp.Diagnostics, "Go plugin v2", path.Root("plugin"), err)
+			return
+		}
+
+		plugin = process.NewTimeoutValueProcessor(plugin, timeout)
+		res, err := plugin.ProcessValue(pluginCtx, input)
+		output.log(ctx)
+		addFindings(&resp.Diagnostics, path.Root("input"), recorder.Findings())
+		if err != nil {
+			addProcessError(&resp.Diagnostics, "Go plugin v2", err)
+			return
+		}
+		tfGoPluginV2.Result = types.StringValue(res.Result)
+		tfGoPluginV2.ResultValue, err = resultValue(ctx, res.Values)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid Go plugin v2 result", "Could not convert the result into a Terraform value: "+err.Error())
+			return
+		}
+	} else {
+		plugin, err := process.NewGoPluginV2Processor(ctx, tfGoPluginV2.Plugin.ValueString(), vars, sandbox)
+		if err != nil {
+			addGoPluginLoadError(&resp.Diagnostics, "Go plugin v2", path.Root("plugin"), err)
+			return
+		}
+
+		plugin = process.NewTimeoutProcessor(plugin, timeout)
+		result, err := plugin.Process(pluginCtx, tfGoPluginV2.InputData.ValueString())
+		output.log(ctx)
+		addFindings(&resp.Diagnostics, path.Root("input_data"), recorder.Findings())
+		if err != nil {
+			addProcessError(&resp.Diagnostics, "Go plugin v2", err)
+			return
+		}
+		tfGoPluginV2.Result = types.StringValue(result)
+		tfGoPluginV2.ResultValue, err = jsonResultValue(ctx, result)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid Go plugin v2 result", "Could not convert the result into a Terraform value: "+err.Error())
+			return
+		}
+	}
+
+	// The error findings fail the execution.
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tfGoPluginV2.Stdout = types.StringValue(output.stdout.String())
+	tfGoPluginV2.Stderr = types.StringValue(output.stderr.String())
+	tfGoPluginV2.Findings, diags = findingsValue(ctx, recorder.Findings())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id, err := contentID("go_plugin_v2", map[string]any{
+		"plugin":     tfGoPluginV2.Plugin.ValueString(),
+		"input_data": tfGoPluginV2.InputData.ValueString(),
+		"input":      input,
+		"vars":       vars,
+		"sandbox":    sandbox,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Error calculating ID", "Could not calculate the ID of the Go plugin v2 execution: "+err.Error())
+		return
+	}
+	tfGoPluginV2.ID = types.StringValue(id)
+
+	diags = resp.State.Set(ctx, tfGoPluginV2)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,262 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// TestAccDataSourceGoPluginV2 will check a go plugin v2 execution.
+func TestAccDataSourceGoPluginV2(t *testing.T) {
+	tests := map[string]struct {
+		config         string
+		expResult      string
+		expResultValue map[string]string
+		expErr         *regexp.Regexp
+	}{
+		"Not having input data should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v2" "test" {
+	input_data = ""
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	return input, nil
+}
+	EOT
+}`,
+			expErr: regexp.MustCompile("Attribute can't be empty"),
+		},
+
+		"A v1 plugin should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v2" "test" {
+	input_data = "{}"
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return inputData, nil
+}
+	EOT
+}`,
+			expErr: regexp.MustCompile(`Could not create Go plugin v2 processor, unexpected error: could not load`),
+		},
+
+		"Simple transparent plugin should return the input transparently.": {
+			config: `
+data "dataprocessor_go_plugin_v2" "test" {
+	input_data = <<EOT
+		{"a": "b", "x": ["y", "z"]}
+	EOT
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	return input, nil
+}
+	EOT
+}`,
+			expResult:      `{"a":"b","x":["y","z"]}`,
+			expResultValue: map[string]string{"result_value.a": "b", "result_value.x.#": "2", "result_value.x.0": "y", "result_value.x.1": "z"},
+		},
+
+		"Input values should be passed to the plugin without encoding them.": {
+			config: `
+data "dataprocessor_go_plugin_v2" "test" {
+	input  = {users = ["a", "b"], limit = 1}
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	m := input.(map[string]any)
+	return m["users"].([]any)[:m["limit"].(int)], nil
+}
+	EOT
+}`,
+			expResult:      `["a"]`,
+			expResultValue: map[string]string{"result_value.#": "1", "result_value.0": "a"},
+		},
+
+		"Input values bigger than the max input size should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v2" "test" {
+	input          = {a = "bbbbbbbbbb"}
+	plugin         = "package testplugin"
+	max_input_size = 10
+}`,
+			expErr: regexp.MustCompile(`input data size \(18 bytes\) exceeds the max input size \(10 bytes\)`),
+		},
+
+		"Input values and input data at the same time should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v2" "test" {
+	input      = {a = 1}
+	input_data = "{}"
+	plugin     = "package testplugin"
+}`,
+			expErr: regexp.MustCompile("Only one of `input` or `input_data` can be set"),
+		},
+
+		"Variables and JSON variables should be typed in the plugin logic.": {
+			config: `
+data "dataprocessor_go_plugin_v2" "test" {
+	input_data = "{}"
+	vars = {
+		name = "test"
+	}
+	json_vars = {
+		count = jsonencode(3)
+		tags  = jsonencode(["a", "b"])
+	}
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	return map[string]any{
+		"name":  vars["name"].(string),
+		"count": vars["count"].(float64) + 1,
+		"tags":  len(vars["tags"].([]any)),
+	}, nil
+}
+	EOT
+}`,
+			expResult:      `{"count":4,"name":"test","tags":2}`,
+			expResultValue: map[string]string{"result_value.count": "4", "result_value.name": "test", "result_value.tags": "2"},
+		},
+
+		"Invalid JSON variables should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v2" "test" {
+	input_data = "{}"
+	json_vars = {
+		count = "{"
+	}
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	return input, nil
+}
+	EOT
+}`,
+			expErr: regexp.MustCompile(`Invalid JSON variable`),
+		},
+
+		"Variables and JSON variables with the same name should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v2" "test" {
+	input_data = "{}"
+	vars = {
+		count = "3"
+	}
+	json_vars = {
+		count = jsonencode(3)
+	}
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	return input, nil
+}
+	EOT
+}`,
+			expErr: regexp.MustCompile(`Variable "count" is already set on vars`),
+		},
+
+		"If the plugin fails, it should fail.": {
+			config: `
+data "dataprocessor_go_plugin_v2" "test" {
+	input_data = "{}"
+	plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+	"fmt"
+)
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	return nil, fmt.Errorf("error from plugin")
+}
+	EOT
+}`,
+			expErr: regexp.MustCompile("Could not process input data, unexpected error: error from plugin"),
+		},
+
+		"The plugin findings should be reported.": {
+			config: `
+data "dataprocessor_go_plugin_v2" "test" {
+	input = {"replicas": 1}
+	plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+func ProcessorPluginV2(ctx context.Context, input any, vars map[string]any) (any, error) {
+	if input.(map[string]any)["replicas"] == 1 {
+		dataprocessor.AddFinding(ctx, dataprocessor.Finding{Severity: dataprocessor.SeverityWarning, Summary: "Single replica", Path: "replicas"})
+	}
+	return input, nil
+}
+	EOT
+}`,
+			expResult: `{"replicas":1}`,
+			expResultValue: map[string]string{
+				"findings.#":          "1",
+				"findings.0.severity": "warning",
+				"findings.0.summary":  "Single replica",
+				"findings.0.path":     "replicas",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Prepare non error checks.
+			var checks resource.TestCheckFunc
+			if test.expErr == nil {
+				checkFuncs := []resource.TestCheckFunc{
+					resource.TestCheckResourceAttr("data.dataprocessor_go_plugin_v2.test", "result", test.expResult),
+				}
+				for k, v := range test.expResultValue {
+					checkFuncs = append(checkFuncs, resource.TestCheckResourceAttr("data.dataprocessor_go_plugin_v2.test", k, v))
+				}
+				checks = resource.ComposeAggregateTestCheckFunc(checkFuncs...)
+			}
+
+			// Check.
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config:      test.config,
+						Check:       checks,
+						ExpectError: test.expErr,
+					},
+				},
+			})
+		})
+	}
+}
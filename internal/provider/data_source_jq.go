@@ -2,74 +2,192 @@ package provider
 
 import (
 	"context"
-	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/slok/terraform-provider-dataprocessor/internal/process"
 	"github.com/slok/terraform-provider-dataprocessor/internal/provider/attributeutils"
 )
 
-type dataSourceJQType struct{}
+var (
+	_ datasource.DataSourceWithConfigure      = &dataSourceJQ{}
+	_ datasource.DataSourceWithValidateConfig = &dataSourceJQ{}
+)
+
+func newDataSourceJQ() datasource.DataSource {
+	return &dataSourceJQ{}
+}
+
+type dataSourceJQ struct {
+	p provider
+}
 
-func (d dataSourceJQType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
-	return tfsdk.Schema{
+func (d *dataSourceJQ) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_jq"
+}
+
+func (d *dataSourceJQ) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
 		Description: `
 Executes a JQ expression providing the result.
+
+The input data can have multiple JSON values (e.g NDJSON), the expression is executed on each of them and the
+` + "`input`" + ` and ` + "`inputs`" + ` builtins consume the next ones. The input can be customized with ` + "`slurp`" + `, ` + "`raw_input`" + ` and
+` + "`null_input`" + `, that mirror jq CLI flags.
+
+The results are rendered in JSON with the objects keys sorted (like jq ` + "`--sort-keys`" + `), the rendering can be customized with
+` + "`raw_output`" + `, ` + "`join_output`" + `, ` + "`ascii_output`" + ` and ` + "`indent`" + `, that mirror jq CLI flags.
+
+The expression can use JQ modules with ` + "`import`" + ` and ` + "`include`" + ` (e.g ` + "`import \"lib\" as lib;`" + `), the modules are set on
+` + "`modules`" + ` or searched on the provider ` + "`jq_library_paths`" + ` directories.
+
+## Functions
+
+On top of the JQ builtins, the expression can use these functions:
+
+- ` + "`sha256`" + `: Hex encoded sha256 checksum of a string (e.g ` + "`\"test\" | sha256`" + `).
+- ` + "`yaml_decode`" + `, ` + "`yaml_encode`" + `: Decode (only the first document) and encode YAML (e.g ` + "`.data | yaml_decode`" + `).
+- ` + "`toml_decode`" + `: Decode TOML, the dates are decoded as strings (e.g ` + "`.data | toml_decode`" + `).
+- ` + "`semver_compare(v)`" + `: Compare semantic versions, returns ` + "`-1`" + `, ` + "`0`" + ` or ` + "`1`" + ` (e.g ` + "`\"1.2.0\" | semver_compare(\"1.10.0\")`" + `).
+- ` + "`cidrsubnet(newbits; netnum)`" + `: Calculate a subnet like terraform ` + "`cidrsubnet`" + ` (e.g ` + "`\"10.0.0.0/16\" | cidrsubnet(8; 2)`" + `).
+- ` + "`cidrcontains(ip)`" + `: Check if a prefix contains an IP or prefix (e.g ` + "`\"10.0.0.0/16\" | cidrcontains(\"10.0.1.5\")`" + `).
+- ` + "`uuid_v5(namespace)`" + `: Name based UUID like terraform ` + "`uuidv5`" + ` (e.g ` + "`\"www.example.com\" | uuid_v5(\"dns\")`" + `).
+- ` + "`strftime_tz(format; timezone)`" + `: Format a unix timestamp or RFC 3339 time on a time zone (e.g ` + "`now | strftime_tz(\"%H:%M\"; \"Europe/Madrid\")`" + `).
 `,
-		Attributes: map[string]tfsdk.Attribute{
-			"expression": {
+		Attributes: map[string]schema.Attribute{
+			"expression": schema.StringAttribute{
 				Description: `The JQ expression to be executed.`,
 				Required:    true,
-				Type:        types.StringType,
-				Validators:  []tfsdk.AttributeValidator{attributeutils.NonEmptyString},
+				Validators:  []validator.String{attributeutils.NonEmptyString},
 			},
-			"input_data": {
-				Description:   `The input JSON data that will be processed with JQ.`,
-				Required:      true,
-				Type:          types.StringType,
-				Validators:    []tfsdk.AttributeValidator{attributeutils.NonEmptyString},
-				PlanModifiers: tfsdk.AttributePlanModifiers{attributeutils.DefaultValue(types.String{Value: "{}"})},
+			"input_data": schema.StringAttribute{
+				Description: "The input JSON data that will be processed with JQ. Required unless `input` is set or `null_input` is enabled.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.NonEmptyString},
 			},
-			"vars": {
+			"input": schema.DynamicAttribute{
+				Description: "The input value that will be processed with JQ, any Terraform value (e.g object, list, string...) is used directly without encoding it with `jsonencode`. Conflicts with `input_data` and can't be used with `raw_input`.",
+				Optional:    true,
+			},
+			"vars": schema.MapAttribute{
 				Description: `Variables that will be passed to JQ execution.`,
 				Optional:    true,
-				Type:        types.MapType{ElemType: types.StringType},
+				ElementType: types.StringType,
+			},
+			"json_vars": schema.MapAttribute{
+				Description: "Variables in JSON format that will be decoded and passed to JQ execution with their type, like jq `--argjson` (e.g `jsonencode(3)` or `jsonencode([\"a\", \"b\"])`). Can't use the same names as `vars`.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"modules": schema.MapAttribute{
+				Description: "JQ modules source code by name, that can be used in the expression with `import` and `include` (e.g `import \"lib\" as lib;`). The modules that are not set will be searched on the provider `jq_library_paths`.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"pretty": schema.BoolAttribute{
+				Description: "If enabled the JSON result will be rendered in pretty format. Defaults to the provider `jq_pretty`.",
+				Optional:    true,
+			},
+			"slurp": schema.BoolAttribute{
+				Description: "Read all the inputs into an array and execute the expression once, like jq `--slurp`. With `raw_input`, all the input data is read as a single string. Defaults to `false`.",
+				Optional:    true,
 			},
-			"pretty": {
-				Description:   `If enabled the JSON result will be rendered in pretty format.`,
-				Optional:      true,
-				Type:          types.BoolType,
-				PlanModifiers: tfsdk.AttributePlanModifiers{attributeutils.DefaultValue(types.Bool{Value: false})},
+			"raw_input": schema.BoolAttribute{
+				Description: "Read each line of the input data as a string instead of JSON, like jq `--raw-input`. Defaults to `false`.",
+				Optional:    true,
+			},
+			"null_input": schema.BoolAttribute{
+				Description: "Execute the expression once with `null` as input, like jq `--null-input`. The input data can be read with `input` and `inputs`. Defaults to `false`.",
+				Optional:    true,
+			},
+			"indent": schema.Int64Attribute{
+				Description: "Render the JSON results in pretty format indented with the number of spaces (up to `7`), like jq `--indent`. By default `pretty` uses tabs.",
+				Optional:    true,
+				Validators:  []validator.Int64{attributeutils.PositiveInt64},
+			},
+			"raw_output": schema.BoolAttribute{
+				Description: "Render the string results without quotes, like jq `--raw-output`. Defaults to `false`.",
+				Optional:    true,
+			},
+			"join_output": schema.BoolAttribute{
+				Description: "Same as `raw_output`, but the results are not separated by newlines, like jq `--join-output`. Defaults to `false`.",
+				Optional:    true,
+			},
+			"ascii_output": schema.BoolAttribute{
+				Description: "Escape the non ASCII characters, like jq `--ascii-output`. Defaults to `false`.",
+				Optional:    true,
+			},
+			"max_input_size": schema.Int64Attribute{
+				Description: "Maximum size in bytes of the input data, the `input` values are measured encoded in JSON. Defaults to the provider `max_input_size`.",
+				Optional:    true,
+				Validators:  []validator.Int64{attributeutils.PositiveInt64},
 			},
-			"result": {
+			"timeout": schema.StringAttribute{
+				Description: "Maximum duration of the processor execution (e.g `30s`, `5m`). Defaults to the provider `timeout`.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.Duration},
+			},
+			"result": schema.StringAttribute{
 				Description: `JQ execution result.`,
 				Computed:    true,
-				Type:        types.StringType,
 			},
-			"id": {
-				Description: `Not used, can be ignored.`,
+			"results": schema.ListAttribute{
+				Description: "Every value emitted by the JQ expression as an independent result, `result` has all of them joined.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"result_value": schema.DynamicAttribute{
+				Description: "JQ execution result decoded as a Terraform value (e.g object, list, number...), so it doesn't need `jsondecode`. With multiple results, it's a list of all of them.",
+				Computed:    true,
+			},
+			"id": schema.StringAttribute{
+				Description: "Hash of the expression, the input, the vars, the JQ options and the modules loaded from `jq_library_paths`, it only changes when the result can change, so it can be used as a change signal (e.g `replace_triggered_by`).",
 				Computed:    true,
-				Type:        types.StringType,
 			},
 		},
-	}, nil
+	}
 }
 
-func (d dataSourceJQType) NewDataSource(ctx context.Context, p tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
-	prv := p.(*provider)
-	return dataSourceJQ{
-		p: *prv,
-	}, nil
+func (d *dataSourceJQ) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// The provider data is not set until the provider has been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.p = *req.ProviderData.(*provider)
 }
 
-type dataSourceJQ struct {
-	p provider
+func (d *dataSourceJQ) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var tfJQ JQ
+	diags := req.Config.Get(ctx, &tfJQ)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateJQ(&resp.Diagnostics, tfJQ)
+}
+
+// validateJQ validates the JQ processor configuration.
+func validateJQ(diags *diag.Diagnostics, tfJQ JQ) {
+	// We can't know yet.
+	if tfJQ.NullInput.IsUnknown() || tfJQ.RawInput.IsUnknown() {
+		return
+	}
+
+	validateInput(diags, tfJQ.InputData, tfJQ.Input, tfJQ.NullInput.ValueBool())
+
+	if !tfJQ.Input.IsNull() && !tfJQ.Input.IsUnknown() && tfJQ.RawInput.ValueBool() {
+		diags.AddAttributeError(path.Root("raw_input"), "Unsupported raw input", "`raw_input` can't be used with `input`, the input is not text.")
+	}
 }
 
-func (d dataSourceJQ) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+func (d *dataSourceJQ) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	if !d.p.configured {
 		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
 		return
@@ -83,30 +201,140 @@ func (d dataSourceJQ) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest,
 		return
 	}
 
-	// Execute JQ.
-	vars := map[string]string{}
-	for k, v := range tfJQ.Vars {
-		vars[k] = v.Value
+	d.p.processJQ(ctx, &resp.Diagnostics, &tfJQ)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	jq, err := process.NewJQProcessor(ctx, tfJQ.Expression.Value, vars, tfJQ.Pretty.Value)
+
+	diags = resp.State.Set(ctx, tfJQ)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// processJQ executes the JQ processor with the configuration and sets the results and the ID on it, the
+// errors are added to the diagnostics.
+func (p provider) processJQ(ctx context.Context, diags *diag.Diagnostics, tfJQ *JQ) {
+	// Check input data limits.
+	err := p.checkInputSize(tfJQ.InputData.ValueString(), tfJQ.MaxInputSize)
 	if err != nil {
-		resp.Diagnostics.AddError("Error creating JQ processor", "Could not create JQ processor, unexpected error: "+err.Error())
+		diags.AddAttributeError(path.Root("input_data"), "Invalid input data", err.Error())
 		return
 	}
 
-	result, err := jq.Process(ctx, tfJQ.InputData.Value)
+	timeout, err := p.processorTimeout(tfJQ.Timeout)
 	if err != nil {
-		resp.Diagnostics.AddError("Error executing JQ processor", "Could not process input data, unexpected error: "+err.Error())
+		diags.AddAttributeError(path.Root("timeout"), "Invalid timeout", err.Error())
 		return
 	}
-	tfJQ.Result = types.String{Value: result}
 
-	// Force execution every time.
-	tfJQ.ID = types.String{Value: time.Now().String()}
+	// Load vars.
+	vars := decodeVars(diags, tfJQ.Vars, tfJQ.JSONVars)
+	if diags.HasError() {
+		return
+	}
 
-	diags = resp.State.Set(ctx, tfJQ)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+	// Execute JQ.
+	pretty := p.defaults.jqPretty
+	if !tfJQ.Pretty.IsNull() && !tfJQ.Pretty.IsUnknown() {
+		pretty = tfJQ.Pretty.ValueBool()
+	}
+	modules := map[string]string{}
+	for k, v := range tfJQ.Modules {
+		modules[k] = v.ValueString()
+	}
+	opts := process.JQOptions{
+		Pretty:       pretty,
+		Indent:       int(tfJQ.Indent.ValueInt64()),
+		RawOutput:    tfJQ.RawOutput.ValueBool(),
+		JoinOutput:   tfJQ.JoinOutput.ValueBool(),
+		ASCIIOutput:  tfJQ.ASCIIOutput.ValueBool(),
+		Slurp:        tfJQ.Slurp.ValueBool(),
+		RawInput:     tfJQ.RawInput.ValueBool(),
+		NullInput:    tfJQ.NullInput.ValueBool(),
+		Modules:      modules,
+		LibraryPaths: p.defaults.jqLibraryPaths,
+	}
+	var res process.Results
+	var input any
+	if !tfJQ.Input.IsNull() {
+		input, err = inputValue(ctx, tfJQ.Input)
+		if err != nil {
+			diags.AddAttributeError(path.Root("input"), "Invalid input", err.Error())
+			return
+		}
+		err = p.checkInputValueSize(input, tfJQ.MaxInputSize)
+		if err != nil {
+			diags.AddAttributeError(path.Root("input"), "Invalid input", err.Error())
+			return
+		}
+
+		jq, err := process.NewJQValueProcessor(ctx, tfJQ.Expression.ValueString(), vars, opts)
+		if err != nil {
+			addProcessorCreateError(diags, "JQ", path.Root("expression"), err)
+			return
+		}
+
+		jq = process.NewTimeoutValueProcessor(jq, timeout)
+		res, err = jq.ProcessValue(ctx, input)
+		if err != nil {
+			addProcessError(diags, "JQ", err)
+			return
+		}
+	} else {
+		jq, err := process.NewJQProcessor(ctx, tfJQ.Expression.ValueString(), vars, opts)
+		if err != nil {
+			addProcessorCreateError(diags, "JQ", path.Root("expression"), err)
+			return
+		}
+
+		jq = process.NewTimeoutMultiResultProcessor(jq, timeout)
+		res, err = jq.ProcessResults(ctx, tfJQ.InputData.ValueString())
+		if err != nil {
+			addProcessError(diags, "JQ", err)
+			return
+		}
+	}
+	tfJQ.Result = types.StringValue(res.Result)
+	results, d := types.ListValueFrom(ctx, types.StringType, res.Results)
+	diags.Append(d...)
+	if diags.HasError() {
 		return
 	}
+	tfJQ.Results = results
+	tfJQ.ResultValue, err = resultValue(ctx, res.Values)
+	if err != nil {
+		diags.AddError("Invalid JQ result", "Could not convert the result into a Terraform value: "+err.Error())
+		return
+	}
+
+	libraryModules, err := jqLibraryModules(tfJQ.Expression.ValueString(), vars, opts)
+	if err != nil {
+		diags.AddError("Error calculating ID", "Could not load the JQ library modules: "+err.Error())
+		return
+	}
+	id, err := contentID("jq", map[string]any{
+		"expression":      tfJQ.Expression.ValueString(),
+		"input_data":      tfJQ.InputData.ValueString(),
+		"input":           input,
+		"vars":            vars,
+		"options":         opts,
+		"library_modules": libraryModules,
+	})
+	if err != nil {
+		diags.AddError("Error calculating ID", "Could not calculate the ID of the JQ execution: "+err.Error())
+		return
+	}
+	tfJQ.ID = types.StringValue(id)
+}
+
+// jqLibraryModules returns the modules loaded from the library paths by the JQ expression, they are not part of the
+// configuration, but the result depends on them, so they are part of the ID.
+func jqLibraryModules(expression string, vars map[string]any, opts process.JQOptions) (map[string]string, error) {
+	if len(opts.LibraryPaths) == 0 {
+		return nil, nil
+	}
+
+	return process.JQLibraryModules(expression, vars, opts)
 }
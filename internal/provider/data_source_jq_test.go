@@ -1,18 +1,23 @@
 package provider_test
 
 import (
+	"fmt"
 	"regexp"
+	"strconv"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 // TestAccDataSourceJQ will check a jq execution.
 func TestAccDataSourceJQ(t *testing.T) {
 	tests := map[string]struct {
-		config    string
-		expResult string
-		expErr    *regexp.Regexp
+		config         string
+		expResult      string
+		expResults     []string
+		expResultValue map[string]string
+		expErr         *regexp.Regexp
 	}{
 		"Not having input data should fail.": {
 			config: `
@@ -38,7 +43,7 @@ data "dataprocessor_jq" "test" {
 	input_data = "{}"
 	expression = ".|()ASd-sda?"
 }`,
-			expErr: regexp.MustCompile(`Could not create JQ processor, unexpected error: could not parse JQ.*`),
+			expErr: regexp.MustCompile(`Could not create JQ processor, could not parse JQ.*`),
 		},
 
 		"Simple transparent JQ execution should return the input transparently.": {
@@ -67,6 +72,84 @@ data "dataprocessor_jq" "test" {
 }`,
 		},
 
+		"The result should be pretty when the provider has pretty enabled by default.": {
+			config: `
+provider "dataprocessor" {
+	jq_pretty = true
+}
+
+data "dataprocessor_jq" "test" {
+	input_data = <<EOT
+		{"a": "b", "x": "y"}
+	EOT
+	expression = "."
+}`,
+			expResult: `{
+	"a": "b",
+	"x": "y"
+}`,
+		},
+
+		"The data source pretty option should override the provider default.": {
+			config: `
+provider "dataprocessor" {
+	jq_pretty = true
+}
+
+data "dataprocessor_jq" "test" {
+	input_data = <<EOT
+		{"a": "b", "x": "y"}
+	EOT
+	expression = "."
+	pretty = false
+}`,
+			expResult: `{"a":"b","x":"y"}`,
+		},
+
+		"An input data bigger than the provider max input size should fail.": {
+			config: `
+provider "dataprocessor" {
+	max_input_size = 10
+}
+
+data "dataprocessor_jq" "test" {
+	input_data = <<EOT
+		{"a": "b", "x": "y"}
+	EOT
+	expression = "."
+}`,
+			expErr: regexp.MustCompile(`exceeds the max input size \(10 bytes\)`),
+		},
+
+		"The data source max input size should override the provider default.": {
+			config: `
+provider "dataprocessor" {
+	max_input_size = 10
+}
+
+data "dataprocessor_jq" "test" {
+	input_data = <<EOT
+		{"a": "b", "x": "y"}
+	EOT
+	expression = "."
+	max_input_size = 1024
+}`,
+			expResult: `{"a":"b","x":"y"}`,
+		},
+
+		"An invalid provider timeout should fail.": {
+			config: `
+provider "dataprocessor" {
+	timeout = "10 minutes"
+}
+
+data "dataprocessor_jq" "test" {
+	input_data = "{}"
+	expression = "."
+}`,
+			expErr: regexp.MustCompile(`Attribute must be a valid duration`),
+		},
+
 		"Variable interpolation should work when vars are user.": {
 			config: `
 data "dataprocessor_jq" "test" {
@@ -78,6 +161,228 @@ data "dataprocessor_jq" "test" {
 }`,
 			expResult: `{"a":"b","extra":"something","x":"y"}`,
 		},
+
+		"JSON variables should be passed with their type.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = "{}"
+	vars       = {"name": "test"}
+	json_vars = {
+		count = jsonencode(3)
+		tags  = jsonencode(["a", "b"])
+	}
+	expression = "{\"name\": $name, \"count\": ($count + 1), \"tags\": $tags}"
+}`,
+			expResult: `{"count":4,"name":"test","tags":["a","b"]}`,
+		},
+
+		"Raw output should render strings without quotes.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = "{\"url\": \"https://example.com/?a=1&b=2\"}"
+	raw_output = true
+	expression = ".url"
+}`,
+			expResult: `https://example.com/?a=1&b=2`,
+		},
+
+		"Indent should render the result indented with spaces.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data   = "{\"a\": \"ñ\"}"
+	indent       = 2
+	ascii_output = true
+	expression   = "."
+}`,
+			expResult: `{
+  "a": "\u00f1"
+}`,
+		},
+
+		"Invalid indent should fail.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = "{}"
+	indent     = 8
+	expression = "."
+}`,
+			expErr: regexp.MustCompile(`invalid indent 8, must be between 0 and 7`),
+		},
+
+		"NDJSON input should execute the expression on every input.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = <<EOT
+{"a": 1}
+{"a": 2}
+EOT
+	slurp      = true
+	expression = "map(.a)"
+}`,
+			expResult: `[1,2]`,
+		},
+
+		"Raw input should read the lines as strings.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = <<EOT
+a
+b
+EOT
+	raw_input  = true
+	raw_output = true
+	expression = "ascii_upcase"
+}`,
+			expResult: "A\nB",
+		},
+
+		"Null input should not require input data.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	null_input = true
+	vars       = {"x": "y"}
+	expression = "{\"x\": $x}"
+}`,
+			expResult: `{"x":"y"}`,
+		},
+
+		"Missing input data without null input should fail.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	expression = "."
+}`,
+			expErr: regexp.MustCompile("One of `input` or `input_data` must be set"),
+		},
+
+		"Input values should be processed without encoding them.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input      = {a = [1, "b"], c = {d = true}}
+	expression = "[.a[0], .c.d]"
+}`,
+			expResult:      `[1,true]`,
+			expResults:     []string{`[1,true]`},
+			expResultValue: map[string]string{"result_value.#": "2", "result_value.0": "1", "result_value.1": "true"},
+		},
+
+		"Input values bigger than the max input size should fail.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input          = {a = "bbbbbbbbbb"}
+	expression     = "."
+	max_input_size = 10
+}`,
+			expErr: regexp.MustCompile(`input data size \(18 bytes\) exceeds the max input size \(10 bytes\)`),
+		},
+
+		"Input values and input data at the same time should fail.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input      = {a = 1}
+	input_data = "{}"
+	expression = "."
+}`,
+			expErr: regexp.MustCompile("Only one of `input` or `input_data` can be set"),
+		},
+
+		"Input values with raw input should fail.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input      = "a"
+	raw_input  = true
+	expression = "."
+}`,
+			expErr: regexp.MustCompile("`raw_input` can't be used with `input`"),
+		},
+
+		"Modules should be imported by the expression.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = "{\"a\": 21}"
+	modules    = {
+		"lib" = "def double: . * 2;"
+	}
+	expression = "import \"lib\" as lib; .a | lib::double"
+}`,
+			expResult: `42`,
+		},
+
+		"Invalid modules should fail with the module line of the error.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = "{}"
+	modules    = {
+		"lib" = "def double: . *;"
+	}
+	expression = "import \"lib\" as lib; ."
+}`,
+			expErr: regexp.MustCompile(`lib:1:16: unexpected token ";"`),
+		},
+
+		"Missing modules should fail.": {
+			config: `
+provider "dataprocessor" {
+	jq_library_paths = ["/missing"]
+}
+
+data "dataprocessor_jq" "test" {
+	input_data = "{}"
+	expression = "import \"lib\" as lib; ."
+}`,
+			expErr: regexp.MustCompile(`module not found`),
+		},
+
+		"Provider functions should be available on the expression.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = "{\"cidr\": \"10.0.0.0/16\", \"version\": \"1.2.0\"}"
+	expression = "[(.cidr | cidrsubnet(8; 2)), (.version | semver_compare(\"1.10.0\"))]"
+}`,
+			expResult: `["10.0.2.0/24",-1]`,
+		},
+
+		"Multiple results should be returned joined and independently.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = "{\"a\": [1, {\"b\": 2}]}"
+	expression = ".a[]"
+}`,
+			expResult:      "1\n{\"b\":2}",
+			expResults:     []string{`1`, `{"b":2}`},
+			expResultValue: map[string]string{"result_value.#": "2", "result_value.0": "1", "result_value.1.b": "2"},
+		},
+
+		"The result should be returned decoded as a Terraform value.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = "{\"a\": {\"b\": [1, \"c\", true]}}"
+	expression = ".a"
+}`,
+			expResult:      `{"b":[1,"c",true]}`,
+			expResults:     []string{`{"b":[1,"c",true]}`},
+			expResultValue: map[string]string{"result_value.b.#": "3", "result_value.b.0": "1", "result_value.b.1": "c", "result_value.b.2": "true"},
+		},
+
+		"Invalid JSON variables should fail.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = "{}"
+	json_vars  = {"count": "{"}
+	expression = "."
+}`,
+			expErr: regexp.MustCompile(`Invalid JSON variable`),
+		},
+
+		"JSON variables with the same name as vars should fail.": {
+			config: `
+data "dataprocessor_jq" "test" {
+	input_data = "{}"
+	vars       = {"count": "3"}
+	json_vars  = {"count": "3"}
+	expression = "."
+}`,
+			expErr: regexp.MustCompile(`Duplicated variable`),
+		},
 	}
 
 	for name, test := range tests {
@@ -85,9 +390,19 @@ data "dataprocessor_jq" "test" {
 			// Prepare non error checks.
 			var checks resource.TestCheckFunc
 			if test.expErr == nil {
-				checks = resource.ComposeAggregateTestCheckFunc(
+				checkFuncs := []resource.TestCheckFunc{
 					resource.TestCheckResourceAttr("data.dataprocessor_jq.test", "result", test.expResult),
-				)
+				}
+				if test.expResults != nil {
+					checkFuncs = append(checkFuncs, resource.TestCheckResourceAttr("data.dataprocessor_jq.test", "results.#", strconv.Itoa(len(test.expResults))))
+					for i, r := range test.expResults {
+						checkFuncs = append(checkFuncs, resource.TestCheckResourceAttr("data.dataprocessor_jq.test", fmt.Sprintf("results.%d", i), r))
+					}
+				}
+				for k, v := range test.expResultValue {
+					checkFuncs = append(checkFuncs, resource.TestCheckResourceAttr("data.dataprocessor_jq.test", k, v))
+				}
+				checks = resource.ComposeAggregateTestCheckFunc(checkFuncs...)
 			}
 
 			// Check.
@@ -105,3 +420,43 @@ data "dataprocessor_jq" "test" {
 		})
 	}
 }
+
+// TestAccDataSourceJQID will check the jq execution ID only depends on the data that determines the result.
+func TestAccDataSourceJQID(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "dataprocessor_jq" "test1" {
+	input_data = "{\"a\": 1}"
+	expression = ".a"
+	timeout    = "30s"
+}
+
+data "dataprocessor_jq" "test2" {
+	input_data = "{\"a\": 1}"
+	expression = ".a"
+}
+
+data "dataprocessor_jq" "test3" {
+	input_data = "{\"a\": 1}"
+	expression = ".a + 1"
+}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("data.dataprocessor_jq.test1", "id", regexp.MustCompile(`^[0-9a-f]{64}$`)),
+					resource.TestCheckResourceAttrPair("data.dataprocessor_jq.test1", "id", "data.dataprocessor_jq.test2", "id"),
+					func(s *terraform.State) error {
+						id2 := s.RootModule().Resources["data.dataprocessor_jq.test2"].Primary.ID
+						id3 := s.RootModule().Resources["data.dataprocessor_jq.test3"].Primary.ID
+						if id2 == id3 {
+							return fmt.Errorf("different expressions should have different IDs")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
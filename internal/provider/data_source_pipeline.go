@@ -0,0 +1,447 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+	"github.com/slok/terraform-provider-dataprocessor/internal/provider/attributeutils"
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+var (
+	_ datasource.DataSourceWithConfigure      = &dataSourcePipeline{}
+	_ datasource.DataSourceWithValidateConfig = &dataSourcePipeline{}
+)
+
+// Pipeline step types.
+const (
+	pipelineStepJQ         = "jq"
+	pipelineStepYQ         = "yq"
+	pipelineStepGoPluginV1 = "go_plugin_v1"
+	pipelineStepGoPluginV2 = "go_plugin_v2"
+)
+
+func newDataSourcePipeline() datasource.DataSource {
+	return &dataSourcePipeline{}
+}
+
+type dataSourcePipeline struct {
+	p provider
+}
+
+func (d *dataSourcePipeline) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pipeline"
+}
+
+func (d *dataSourcePipeline) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: `
+Executes a pipeline of processors providing the result.
+
+The steps are executed in order, the first step receives the input data and the result of each step is the input data
+of the next one, the result of the last step is the pipeline result. This avoids chaining multiple data sources
+with ` + "`result`" + ` and ` + "`input_data`" + `.
+
+Each step has a ` + "`type`" + ` with its settings:
+
+- ` + "`jq`" + `: Executes the JQ ` + "`expression`" + `, the results are rendered in JSON unless ` + "`raw_output`" + ` is enabled.
+- ` + "`yq`" + `: Executes the YQ ` + "`expression`" + `, the data formats are set with ` + "`input_format`" + ` and ` + "`output_format`" + `.
+- ` + "`go_plugin_v1`" + `: Executes the Go plugin v1 ` + "`plugin`" + `.
+- ` + "`go_plugin_v2`" + `: Executes the Go plugin v2 ` + "`plugin`" + `, the input data must be JSON.
+
+The steps use the provider settings (e.g ` + "`jq_pretty`" + `, ` + "`jq_library_paths`" + ` or ` + "`go_plugin_sandbox`" + `), the ` + "`timeout`" + ` and
+` + "`max_input_size`" + ` limits apply to the whole pipeline. The findings reported by the Go plugin steps are shown on their step.
+`,
+		Attributes: map[string]schema.Attribute{
+			"input_data": schema.StringAttribute{
+				Description: "The input data that will be processed by the first step. Required unless `input` is set.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.NonEmptyString},
+			},
+			"input": schema.DynamicAttribute{
+				Description: "The input value that will be processed by the first step, any Terraform value (e.g object, list, string...) is encoded in JSON (strings are used as they are). Conflicts with `input_data`.",
+				Optional:    true,
+			},
+			"max_input_size": schema.Int64Attribute{
+				Description: "Maximum size in bytes of the input data, the `input` values are measured encoded in JSON. Defaults to the provider `max_input_size`.",
+				Optional:    true,
+				Validators:  []validator.Int64{attributeutils.PositiveInt64},
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Maximum duration of the whole pipeline execution (e.g `30s`, `5m`). Defaults to the provider `timeout`.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.Duration},
+			},
+			"result": schema.StringAttribute{
+				Description: `Pipeline execution result, the result of the last step.`,
+				Computed:    true,
+			},
+			"result_value": schema.DynamicAttribute{
+				Description: "Pipeline execution result decoded as a Terraform value, if the result is not JSON, it will be the result string.",
+				Computed:    true,
+			},
+			"stdout":   stdoutAttribute(),
+			"stderr":   stderrAttribute(),
+			"findings": findingsAttribute(),
+			"id": schema.StringAttribute{
+				Description: "Hash of the input, the steps and the modules loaded from `jq_library_paths` by the JQ steps, it only changes when the result can change, so it can be used as a change signal (e.g `replace_triggered_by`).",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"step": schema.ListNestedBlock{
+				Description: "The pipeline steps, executed in order. At least one step is required.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Description: "The step processor type: `jq`, `yq`, `go_plugin_v1` or `go_plugin_v2`.",
+							Required:    true,
+							Validators:  []validator.String{attributeutils.OneOf(pipelineStepTypes()...)},
+						},
+						"expression": schema.StringAttribute{
+							Description: "The JQ or YQ expression to be executed. Required by `jq` and `yq` steps.",
+							Optional:    true,
+							Validators:  []validator.String{attributeutils.NonEmptyString},
+						},
+						"plugin": schema.StringAttribute{
+							Description: "The Go plugin source code. Required by `go_plugin_v1` and `go_plugin_v2` steps. The loaded plugins are reused between executions, the package level variables are not reset, so the plugins should not keep state on them.",
+							Optional:    true,
+							Validators:  []validator.String{attributeutils.NonEmptyString},
+						},
+						"vars": schema.MapAttribute{
+							Description: "Variables that will be passed to the step processor.",
+							Optional:    true,
+							ElementType: types.StringType,
+						},
+						"raw_output": schema.BoolAttribute{
+							Description: "Render the string results without quotes, like jq `--raw-output`. Only for `jq` steps. Defaults to `false`.",
+							Optional:    true,
+						},
+						"input_format": schema.StringAttribute{
+							Description: "The format of the step input data. Only for `yq` steps. Defaults to `yaml`.",
+							Optional:    true,
+							Validators:  []validator.String{attributeutils.OneOf(yqFormats()...)},
+						},
+						"output_format": schema.StringAttribute{
+							Description: "The format of the step result. Only for `yq` steps. Defaults to `yaml`.",
+							Optional:    true,
+							Validators:  []validator.String{attributeutils.OneOf(yqFormats()...)},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *dataSourcePipeline) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// The provider data is not set until the provider has been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.p = *req.ProviderData.(*provider)
+}
+
+func (d *dataSourcePipeline) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var tfPipeline Pipeline
+	diags := req.Config.Get(ctx, &tfPipeline)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateInput(&resp.Diagnostics, tfPipeline.InputData, tfPipeline.Input, false)
+
+	// We can't know yet.
+	if tfPipeline.Steps.IsUnknown() {
+		return
+	}
+
+	var steps []PipelineStep
+	diags = tfPipeline.Steps.ElementsAs(ctx, &steps, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if len(steps) == 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("step"), "Missing pipeline steps", "At least one `step` is required.")
+		return
+	}
+
+	for i, step := range steps {
+		validatePipelineStep(&resp.Diagnostics, path.Root("step").AtListIndex(i), step)
+	}
+}
+
+// validatePipelineStep validates a pipeline step only has the settings of its type.
+func validatePipelineStep(diags *diag.Diagnostics, stepPath path.Path, step PipelineStep) {
+	// We can't know yet.
+	if step.Type.IsUnknown() {
+		return
+	}
+
+	stepType := step.Type.ValueString()
+	isPlugin := stepType == pipelineStepGoPluginV1 || stepType == pipelineStepGoPluginV2
+
+	switch {
+	case !isPlugin && step.Expression.IsNull():
+		diags.AddAttributeError(stepPath.AtName("expression"), "Missing step expression", fmt.Sprintf("`expression` is required by `%s` steps.", stepType))
+	case !isPlugin && !step.Plugin.IsNull():
+		diags.AddAttributeError(stepPath.AtName("plugin"), "Unsupported step plugin", fmt.Sprintf("`plugin` can't be used by `%s` steps.", stepType))
+	case isPlugin && step.Plugin.IsNull():
+		diags.AddAttributeError(stepPath.AtName("plugin"), "Missing step plugin", fmt.Sprintf("`plugin` is required by `%s` steps.", stepType))
+	case isPlugin && !step.Expression.IsNull():
+		diags.AddAttributeError(stepPath.AtName("expression"), "Unsupported step expression", fmt.Sprintf("`expression` can't be used by `%s` steps.", stepType))
+	}
+
+	if stepType != pipelineStepJQ && !step.RawOutput.IsNull() {
+		diags.AddAttributeError(stepPath.AtName("raw_output"), "Unsupported step setting", fmt.Sprintf("`raw_output` can't be used by `%s` steps.", stepType))
+	}
+
+	if stepType != pipelineStepYQ && !step.InputFormat.IsNull() {
+		diags.AddAttributeError(stepPath.AtName("input_format"), "Unsupported step setting", fmt.Sprintf("`input_format` can't be used by `%s` steps.", stepType))
+	}
+
+	if stepType != pipelineStepYQ && !step.OutputFormat.IsNull() {
+		diags.AddAttributeError(stepPath.AtName("output_format"), "Unsupported step setting", fmt.Sprintf("`output_format` can't be used by `%s` steps.", stepType))
+	}
+}
+
+func (d *dataSourcePipeline) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if !d.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
+	}
+
+	// Retrieve values.
+	var tfPipeline Pipeline
+	diags := req.Config.Get(ctx, &tfPipeline)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var steps []PipelineStep
+	diags = tfPipeline.Steps.ElementsAs(ctx, &steps, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The steps communicate with strings, the input values are encoded.
+	inputData := tfPipeline.InputData.ValueString()
+	if !tfPipeline.Input.IsNull() {
+		input, err := inputValue(ctx, tfPipeline.Input)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("input"), "Invalid input", err.Error())
+			return
+		}
+
+		inputData, err = inputString(input)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("input"), "Invalid input", err.Error())
+			return
+		}
+	}
+
+	// Check input data limits.
+	err := d.p.checkInputSize(inputData, tfPipeline.MaxInputSize)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(inputPath(tfPipeline.Input), "Invalid input data", err.Error())
+		return
+	}
+
+	timeout, err := d.p.processorTimeout(tfPipeline.Timeout)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("timeout"), "Invalid timeout", err.Error())
+		return
+	}
+
+	// Create the steps processors, every step records its own plugin findings and logs, so they are reported on the step.
+	processors := make([]process.Processor, 0, len(steps))
+	recorders := make([]*dataprocessor.Recorder, 0, len(steps))
+	for i, step := range steps {
+		processor, err := d.p.pipelineStepProcessor(ctx, step)
+		if err != nil {
+			addPipelineStepCreateError(&resp.Diagnostics, i, step, err)
+			return
+		}
+		recorder := &dataprocessor.Recorder{}
+		processors = append(processors, pipelineStepPluginProcessor(processor, i, recorder))
+		recorders = append(recorders, recorder)
+	}
+
+	// Execute pipeline, the findings and the plugins output are reported even if the pipeline fails, they can
+	// explain the failure.
+	output := &goPluginOutput{}
+	pipeline := process.NewTimeoutProcessor(process.NewPipelineProcessor(processors...), timeout)
+	result, err := pipeline.Process(output.context(ctx), inputData)
+	output.log(ctx)
+	findings := []dataprocessor.Finding{}
+	for i, r := range recorders {
+		addFindings(&resp.Diagnostics, path.Root("step").AtListIndex(i), r.Findings())
+		findings = append(findings, r.Findings()...)
+	}
+	if err != nil {
+		var stepErr process.PipelineStepError
+		if errors.As(err, &stepErr) {
+			resp.Diagnostics.AddAttributeError(path.Root("step").AtListIndex(stepErr.Step), "Error executing pipeline step", fmt.Sprintf("Pipeline step[%d] (%s) could not process its input data, unexpected error: %s", stepErr.Step, steps[stepErr.Step].Type.ValueString(), stepErr.Err))
+			return
+		}
+
+		addProcessError(&resp.Diagnostics, "pipeline", err)
+		return
+	}
+	// The error findings fail the execution.
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tfPipeline.Result = types.StringValue(result)
+
+	// The results are strings, if the result is JSON we can decode it.
+	tfPipeline.ResultValue = types.DynamicValue(types.StringValue(result))
+	if v, err := jsonResultValue(ctx, result); err == nil {
+		tfPipeline.ResultValue = v
+	}
+
+	tfPipeline.Stdout = types.StringValue(output.stdout.String())
+	tfPipeline.Stderr = types.StringValue(output.stderr.String())
+	tfPipeline.Findings, diags = findingsValue(ctx, findings)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	stepsData := make([]map[string]any, 0, len(steps))
+	for i, step := range steps {
+		stepData := map[string]any{
+			"type":          step.Type.ValueString(),
+			"expression":    step.Expression.ValueString(),
+			"plugin":        step.Plugin.ValueString(),
+			"vars":          stringMap(step.Vars),
+			"raw_output":    step.RawOutput.ValueBool(),
+			"input_format":  step.InputFormat.ValueString(),
+			"output_format": step.OutputFormat.ValueString(),
+		}
+		if step.Type.ValueString() == pipelineStepJQ {
+			libraryModules, err := jqLibraryModules(step.Expression.ValueString(), pipelineStepJQVars(step), d.p.pipelineStepJQOptions(step))
+			if err != nil {
+				resp.Diagnostics.AddError("Error calculating ID", fmt.Sprintf("Could not load the step[%d] (jq) library modules: %s", i, err))
+				return
+			}
+			stepData["library_modules"] = libraryModules
+		}
+		stepsData = append(stepsData, stepData)
+	}
+	id, err := contentID("pipeline", map[string]any{
+		"input_data": inputData,
+		"steps":      stepsData,
+		"jq_pretty":  d.p.defaults.jqPretty,
+		"sandbox":    d.p.defaults.goPluginSandbox,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Error calculating ID", "Could not calculate the ID of the pipeline execution: "+err.Error())
+		return
+	}
+	tfPipeline.ID = types.StringValue(id)
+
+	diags = resp.State.Set(ctx, tfPipeline)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// pipelineStepProcessor returns the processor of a pipeline step, the steps use the provider settings.
+func (p provider) pipelineStepProcessor(ctx context.Context, step PipelineStep) (process.Processor, error) {
+	vars := stringMap(step.Vars)
+
+	switch step.Type.ValueString() {
+	case pipelineStepJQ:
+		return process.NewJQProcessor(ctx, step.Expression.ValueString(), pipelineStepJQVars(step), p.pipelineStepJQOptions(step))
+
+	case pipelineStepYQ:
+		return process.NewYQProcessor(ctx, step.Expression.ValueString(), vars, process.YQOptions{
+			InputFormat:  process.YQFormat(step.InputFormat.ValueString()),
+			OutputFormat: process.YQFormat(step.OutputFormat.ValueString()),
+		})
+
+	case pipelineStepGoPluginV1:
+		return process.NewGoPluginV1Processor(ctx, step.Plugin.ValueString(), vars, p.defaults.goPluginSandbox)
+
+	case pipelineStepGoPluginV2:
+		v2Vars := map[string]any{}
+		for k, v := range vars {
+			v2Vars[k] = v
+		}
+		return process.NewGoPluginV2Processor(ctx, step.Plugin.ValueString(), v2Vars, p.defaults.goPluginSandbox)
+	}
+
+	return nil, fmt.Errorf("unsupported step type %q", step.Type.ValueString())
+}
+
+// addPipelineStepCreateError adds the error of a step processor creation to the diagnostics, if the error is at a
+// known position of the step source, it's added on the step source attribute with the source line.
+func addPipelineStepCreateError(diags *diag.Diagnostics, i int, step PipelineStep, err error) {
+	stepPath := path.Root("step").AtListIndex(i)
+
+	var srcErr process.SourceError
+	if errors.As(err, &srcErr) {
+		if srcErr.Source != "" {
+			diags.AddAttributeError(stepPath, "Error creating pipeline step processor", fmt.Sprintf("Could not create step[%d] (%s) processor, %s", i, step.Type.ValueString(), sourceErrorMessage(err)))
+			return
+		}
+
+		srcAttr := "expression"
+		if step.Type.ValueString() == pipelineStepGoPluginV1 || step.Type.ValueString() == pipelineStepGoPluginV2 {
+			srcAttr = "plugin"
+		}
+		diags.AddAttributeError(stepPath.AtName(srcAttr), "Error creating pipeline step processor", fmt.Sprintf("Could not create step[%d] (%s) processor, %s", i, step.Type.ValueString(), sourceErrorMessage(err)))
+		return
+	}
+
+	diags.AddAttributeError(stepPath, "Error creating pipeline step processor", fmt.Sprintf("Could not create step[%d] (%s) processor, unexpected error: %s", i, step.Type.ValueString(), err))
+}
+
+// pipelineStepJQVars returns the vars of a JQ step.
+func pipelineStepJQVars(step PipelineStep) map[string]any {
+	vars := map[string]any{}
+	for k, v := range step.Vars {
+		vars[k] = v.ValueString()
+	}
+	return vars
+}
+
+// pipelineStepJQOptions returns the options of a JQ step, the steps use the provider settings.
+func (p provider) pipelineStepJQOptions(step PipelineStep) process.JQOptions {
+	return process.JQOptions{
+		Pretty:       p.defaults.jqPretty,
+		RawOutput:    step.RawOutput.ValueBool(),
+		LibraryPaths: p.defaults.jqLibraryPaths,
+	}
+}
+
+// pipelineStepPluginProcessor returns a processor that records on r the findings reported by the plugins of the step
+// processor, and adds the step to their logs.
+func pipelineStepPluginProcessor(p process.Processor, step int, r *dataprocessor.Recorder) process.Processor {
+	return process.ProcessorFunc(func(ctx context.Context, inputData string) (string, error) {
+		ctx = tflog.SetField(ctx, "pipeline_step", step)
+		return p.Process(dataprocessor.ContextWithRecorder(ctx, r), inputData)
+	})
+}
+
+func pipelineStepTypes() []string {
+	return []string{pipelineStepJQ, pipelineStepYQ, pipelineStepGoPluginV1, pipelineStepGoPluginV2}
+}
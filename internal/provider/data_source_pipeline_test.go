@@ -0,0 +1,197 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// TestAccDataSourcePipeline will check a pipeline execution.
+func TestAccDataSourcePipeline(t *testing.T) {
+	tests := map[string]struct {
+		config         string
+		expResult      string
+		expResultValue map[string]string
+		expErr         *regexp.Regexp
+	}{
+		"Not having steps should fail.": {
+			config: `
+data "dataprocessor_pipeline" "test" {
+	input_data = "{}"
+}`,
+			expErr: regexp.MustCompile("Missing pipeline steps"),
+		},
+
+		"A JQ step without expression should fail.": {
+			config: `
+data "dataprocessor_pipeline" "test" {
+	input_data = "{}"
+
+	step {
+		type = "jq"
+	}
+}`,
+			expErr: regexp.MustCompile("Missing step expression"),
+		},
+
+		"A Go plugin step with an expression should fail.": {
+			config: `
+data "dataprocessor_pipeline" "test" {
+	input_data = "{}"
+
+	step {
+		type       = "go_plugin_v1"
+		expression = "."
+		plugin     = "package tf"
+	}
+}`,
+			expErr: regexp.MustCompile("Unsupported step expression"),
+		},
+
+		"A non YQ step with an input format should fail.": {
+			config: `
+data "dataprocessor_pipeline" "test" {
+	input_data = "{}"
+
+	step {
+		type         = "jq"
+		expression   = "."
+		input_format = "json"
+	}
+}`,
+			expErr: regexp.MustCompile("`input_format` can't be used by `jq` steps"),
+		},
+
+		"An invalid step expression should fail reporting the step.": {
+			config: `
+data "dataprocessor_pipeline" "test" {
+	input_data = "{}"
+
+	step {
+		type       = "jq"
+		expression = "."
+	}
+
+	step {
+		type       = "jq"
+		expression = ".|()"
+	}
+}`,
+			expErr: regexp.MustCompile(`Could not create step\[1\] \(jq\) processor, could not parse JQ expression`),
+		},
+
+		"A failed step should fail reporting the step.": {
+			config: `
+data "dataprocessor_pipeline" "test" {
+	input_data = "{}"
+
+	step {
+		type       = "jq"
+		expression = "."
+	}
+
+	step {
+		type       = "jq"
+		expression = "error(\"something\")"
+	}
+}`,
+			expErr: regexp.MustCompile(`Pipeline step\[1\] \(jq\) could not process its input data`),
+		},
+
+		"A plugin step error finding should fail reporting the finding.": {
+			config: `
+data "dataprocessor_pipeline" "test" {
+	input_data = "{}"
+
+	step {
+		type   = "go_plugin_v1"
+		plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	dataprocessor.AddFinding(ctx, dataprocessor.Finding{Summary: "Invalid data", Detail: "the data is empty"})
+	return inputData, nil
+}
+	EOT
+	}
+}`,
+			expErr: regexp.MustCompile(`(?s)Invalid data.*the data is empty`),
+		},
+
+		"The steps should be executed in order with the previous step result.": {
+			config: `
+data "dataprocessor_pipeline" "test" {
+	input = {"users": ["alice", "bob"]}
+
+	step {
+		type       = "jq"
+		expression = "{names: .users}"
+	}
+
+	step {
+		type   = "go_plugin_v1"
+		vars   = {"name": "carol"}
+		plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+	"strings"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return strings.ReplaceAll(inputData, "alice", vars["name"]), nil
+}
+	EOT
+	}
+
+	step {
+		type          = "yq"
+		expression    = ".names"
+		output_format = "json"
+	}
+}`,
+			expResult: `[
+  "carol",
+  "bob"
+]`,
+			expResultValue: map[string]string{"result_value.#": "2", "result_value.0": "carol", "result_value.1": "bob"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Prepare non error checks.
+			var checks resource.TestCheckFunc
+			if test.expErr == nil {
+				checkFuncs := []resource.TestCheckFunc{
+					resource.TestCheckResourceAttr("data.dataprocessor_pipeline.test", "result", test.expResult),
+				}
+				for k, v := range test.expResultValue {
+					checkFuncs = append(checkFuncs, resource.TestCheckResourceAttr("data.dataprocessor_pipeline.test", k, v))
+				}
+				checks = resource.ComposeAggregateTestCheckFunc(checkFuncs...)
+			}
+
+			// Check.
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config:      test.config,
+						Check:       checks,
+						ExpectError: test.expErr,
+					},
+				},
+			})
+		})
+	}
+}
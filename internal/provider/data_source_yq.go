@@ -2,63 +2,167 @@ package provider
 
 import (
 	"context"
-	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 
 	"github.com/slok/terraform-provider-dataprocessor/internal/process"
 	"github.com/slok/terraform-provider-dataprocessor/internal/provider/attributeutils"
 )
 
-type dataSourceYQType struct{}
+var (
+	_ datasource.DataSourceWithConfigure      = &dataSourceYQ{}
+	_ datasource.DataSourceWithValidateConfig = &dataSourceYQ{}
+)
+
+func newDataSourceYQ() datasource.DataSource {
+	return &dataSourceYQ{}
+}
+
+type dataSourceYQ struct {
+	p provider
+}
 
-func (d dataSourceYQType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
-	return tfsdk.Schema{
+func (d *dataSourceYQ) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_yq"
+}
+
+func (d *dataSourceYQ) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
 		Description: `
 Executes a YQ expression providing the result.
+
+The vars are available on the expression as yq variables (e.g ` + "`$name`" + `), the values are always strings.
+
+The input data and the result use YAML by default, other formats can be used with ` + "`input_format`" + ` and ` + "`output_format`" + `,
+e.g read an XML and get the result as JSON to load it with ` + "`jsondecode`" + `. XML and properties inputs can't be used
+with CSV and TSV outputs. TOML is not supported by the YQ version of the provider, TOML data can be decoded with the
+` + "`toml_decode`" + ` function of the JQ data source.
+
+The result encoding can be customized with ` + "`indent`" + ` (YAML, JSON and XML outputs), ` + "`unwrap_scalar`" + ` (YAML and properties outputs),
+` + "`print_document_separators`" + ` and ` + "`style`" + ` (YAML output), using them with other outputs will fail.
 `,
-		Attributes: map[string]tfsdk.Attribute{
-			"expression": {
+		Attributes: map[string]schema.Attribute{
+			"expression": schema.StringAttribute{
 				Description: `The YQ expression to be executed.`,
 				Required:    true,
-				Type:        types.StringType,
-				Validators:  []tfsdk.AttributeValidator{attributeutils.NonEmptyString},
+				Validators:  []validator.String{attributeutils.NonEmptyString},
+			},
+			"input_data": schema.StringAttribute{
+				Description: "The input data that will be processed with YQ, in the `input_format` format. Required unless `input` is set.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.NonEmptyString},
+			},
+			"input": schema.DynamicAttribute{
+				Description: "The input value that will be processed with YQ, any Terraform value (e.g object, list, string...) is used directly as the input document without encoding it, so `input_format` is ignored. Conflicts with `input_data`.",
+				Optional:    true,
+			},
+			"vars": schema.MapAttribute{
+				Description: `Variables that will be passed to YQ execution.`,
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"input_format": schema.StringAttribute{
+				Description: "The format of the input data (`yaml`, `json`, `xml`, `props`, `csv` or `tsv`), TOML is not supported. Defaults to `yaml`.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.OneOf(yqFormats()...)},
+			},
+			"output_format": schema.StringAttribute{
+				Description: "The format of the result (`yaml`, `json`, `xml`, `props`, `csv` or `tsv`). Defaults to `yaml`.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.OneOf(yqFormats()...)},
+			},
+			"indent": schema.Int64Attribute{
+				Description: "The indentation of the result. Defaults to `2`.",
+				Optional:    true,
+				Validators:  []validator.Int64{attributeutils.PositiveInt64},
+			},
+			"unwrap_scalar": schema.BoolAttribute{
+				Description: "Print scalar results without quotes and tags. Defaults to `true`.",
+				Optional:    true,
+			},
+			"print_document_separators": schema.BoolAttribute{
+				Description: "Print the `---` separators between the result documents. Defaults to `false`.",
+				Optional:    true,
 			},
-			"input_data": {
-				Description:   `The input YAML data that will be processed with YQ.`,
-				Required:      true,
-				Type:          types.StringType,
-				Validators:    []tfsdk.AttributeValidator{attributeutils.NonEmptyString},
-				PlanModifiers: tfsdk.AttributePlanModifiers{attributeutils.DefaultValue(types.String{Value: "{}"})},
+			"style": schema.StringAttribute{
+				Description: "The style applied to all the result nodes (`tagged`, `double`, `single`, `literal`, `folded` or `flow`). By default the input style is kept.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.OneOf(yqStyles()...)},
 			},
-			"result": {
+			"max_input_size": schema.Int64Attribute{
+				Description: "Maximum size in bytes of the input data, the `input` values are measured encoded in JSON. Defaults to the provider `max_input_size`.",
+				Optional:    true,
+				Validators:  []validator.Int64{attributeutils.PositiveInt64},
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Maximum duration of the processor execution (e.g `30s`, `5m`). yq can't be stopped while it evaluates a document, when it times out the execution fails but the evaluation of the running document keeps running in the background until it finishes. Defaults to the provider `timeout`.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.Duration},
+			},
+			"result": schema.StringAttribute{
 				Description: `YQ execution result.`,
 				Computed:    true,
-				Type:        types.StringType,
 			},
-			"id": {
-				Description: `Not used, can be ignored.`,
+			"results": schema.ListAttribute{
+				Description: "Every result node (e.g every document) of the YQ execution as an independent result, `result` has all of them joined.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"result_value": schema.DynamicAttribute{
+				Description: "YQ execution result decoded as a Terraform value (e.g object, list, number...), so it doesn't need `yamldecode` or `jsondecode`. With multiple results (e.g multiple documents), it's a list of all of them.",
+				Computed:    true,
+			},
+			"id": schema.StringAttribute{
+				Description: "Hash of the expression, the input, the vars and the YQ options, it only changes when the result can change, so it can be used as a change signal (e.g `replace_triggered_by`).",
 				Computed:    true,
-				Type:        types.StringType,
 			},
 		},
-	}, nil
+	}
 }
 
-func (d dataSourceYQType) NewDataSource(ctx context.Context, p tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
-	prv := p.(*provider)
-	return dataSourceYQ{
-		p: *prv,
-	}, nil
+func (d *dataSourceYQ) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// The provider data is not set until the provider has been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	d.p = *req.ProviderData.(*provider)
 }
 
-type dataSourceYQ struct {
-	p provider
+func (d *dataSourceYQ) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var tfYQ YQ
+	diags := req.Config.Get(ctx, &tfYQ)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateYQ(&resp.Diagnostics, tfYQ)
 }
 
-func (d dataSourceYQ) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+// validateYQ validates the YQ processor configuration.
+func validateYQ(diags *diag.Diagnostics, tfYQ YQ) {
+	validateInput(diags, tfYQ.InputData, tfYQ.Input, false)
+
+	// We can't know yet.
+	if tfYQ.InputFormat.IsUnknown() || tfYQ.OutputFormat.IsUnknown() || tfYQ.Indent.IsUnknown() ||
+		tfYQ.UnwrapScalar.IsUnknown() || tfYQ.PrintDocumentSeparators.IsUnknown() || tfYQ.Style.IsUnknown() {
+		return
+	}
+
+	err := yqOptions(tfYQ).Validate()
+	if err != nil {
+		diags.AddAttributeError(path.Root("output_format"), "Unsupported output options", "The output options can't be used together: "+err.Error())
+		return
+	}
+}
+
+func (d *dataSourceYQ) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	if !d.p.configured {
 		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
 		return
@@ -72,26 +176,114 @@ func (d dataSourceYQ) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest,
 		return
 	}
 
-	// Execute yq.
-	yq, err := process.NewYQProcessor(ctx, tfYQ.Expression.Value)
+	d.p.processYQ(ctx, &resp.Diagnostics, &tfYQ)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, tfYQ)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// processYQ executes the YQ processor with the configuration and sets the results and the ID on it, the
+// errors are added to the diagnostics.
+func (p provider) processYQ(ctx context.Context, diags *diag.Diagnostics, tfYQ *YQ) {
+	// Check input data limits.
+	err := p.checkInputSize(tfYQ.InputData.ValueString(), tfYQ.MaxInputSize)
 	if err != nil {
-		resp.Diagnostics.AddError("Error creating YQ processor", "Could not create YQ processor, unexpected error: "+err.Error())
+		diags.AddAttributeError(path.Root("input_data"), "Invalid input data", err.Error())
 		return
 	}
 
-	result, err := yq.Process(ctx, tfYQ.InputData.Value)
+	timeout, err := p.processorTimeout(tfYQ.Timeout)
 	if err != nil {
-		resp.Diagnostics.AddError("Error executing YQ processor", "Could not process input data, unexpected error: "+err.Error())
+		diags.AddAttributeError(path.Root("timeout"), "Invalid timeout", err.Error())
 		return
 	}
-	tfYQ.Result = types.String{Value: result}
 
-	// Force execution every time.
-	tfYQ.ID = types.String{Value: time.Now().String()}
+	// Execute yq.
+	vars := map[string]string{}
+	for k, v := range tfYQ.Vars {
+		vars[k] = v.ValueString()
+	}
+	var res process.Results
+	var input any
+	if !tfYQ.Input.IsNull() {
+		input, err = inputValue(ctx, tfYQ.Input)
+		if err != nil {
+			diags.AddAttributeError(path.Root("input"), "Invalid input", err.Error())
+			return
+		}
+		err = p.checkInputValueSize(input, tfYQ.MaxInputSize)
+		if err != nil {
+			diags.AddAttributeError(path.Root("input"), "Invalid input", err.Error())
+			return
+		}
 
-	diags = resp.State.Set(ctx, tfYQ)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+		yq, err := process.NewYQValueProcessor(ctx, tfYQ.Expression.ValueString(), vars, yqOptions(*tfYQ))
+		if err != nil {
+			addProcessorCreateError(diags, "YQ", path.Root("expression"), err)
+			return
+		}
+
+		yq = process.NewTimeoutValueProcessor(yq, timeout)
+		res, err = yq.ProcessValue(ctx, input)
+		if err != nil {
+			addProcessError(diags, "YQ", err)
+			return
+		}
+	} else {
+		yq, err := process.NewYQProcessor(ctx, tfYQ.Expression.ValueString(), vars, yqOptions(*tfYQ))
+		if err != nil {
+			addProcessorCreateError(diags, "YQ", path.Root("expression"), err)
+			return
+		}
+
+		yq = process.NewTimeoutMultiResultProcessor(yq, timeout)
+		res, err = yq.ProcessResults(ctx, tfYQ.InputData.ValueString())
+		if err != nil {
+			addProcessError(diags, "YQ", err)
+			return
+		}
+	}
+	tfYQ.Result = types.StringValue(res.Result)
+	results, d := types.ListValueFrom(ctx, types.StringType, res.Results)
+	diags.Append(d...)
+	if diags.HasError() {
 		return
 	}
+	tfYQ.Results = results
+	tfYQ.ResultValue, err = resultValue(ctx, res.Values)
+	if err != nil {
+		diags.AddError("Invalid YQ result", "Could not convert the result into a Terraform value: "+err.Error())
+		return
+	}
+
+	id, err := contentID("yq", map[string]any{
+		"expression": tfYQ.Expression.ValueString(),
+		"input_data": tfYQ.InputData.ValueString(),
+		"input":      input,
+		"vars":       vars,
+		"options":    yqOptions(*tfYQ),
+	})
+	if err != nil {
+		diags.AddError("Error calculating ID", "Could not calculate the ID of the YQ execution: "+err.Error())
+		return
+	}
+	tfYQ.ID = types.StringValue(id)
+}
+
+// yqOptions returns the yq processor options from the data source configuration, unset values use the yq defaults.
+func yqOptions(tfYQ YQ) process.YQOptions {
+	return process.YQOptions{
+		InputFormat:             process.YQFormat(tfYQ.InputFormat.ValueString()),
+		OutputFormat:            process.YQFormat(tfYQ.OutputFormat.ValueString()),
+		Indent:                  int(tfYQ.Indent.ValueInt64()),
+		WrapScalar:              !tfYQ.UnwrapScalar.IsNull() && !tfYQ.UnwrapScalar.ValueBool(),
+		PrintDocumentSeparators: tfYQ.PrintDocumentSeparators.ValueBool(),
+		Style:                   process.YQStyle(tfYQ.Style.ValueString()),
+	}
 }
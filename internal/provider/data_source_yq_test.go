@@ -1,18 +1,22 @@
 package provider_test
 
 import (
+	"fmt"
 	"regexp"
+	"strconv"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 // TestAccDataSourceYQ will check a yq execution.
 func TestAccDataSourceYQ(t *testing.T) {
 	tests := map[string]struct {
-		config    string
-		expResult string
-		expErr    *regexp.Regexp
+		config         string
+		expResult      string
+		expResults     []string
+		expResultValue map[string]string
+		expErr         *regexp.Regexp
 	}{
 		"Not having input data should fail.": {
 			config: `
@@ -38,7 +42,7 @@ data "dataprocessor_yq" "test" {
 	input_data = "{}"
 	expression = ".|()ASd-sda?"
 }`,
-			expErr: regexp.MustCompile(`Could not process input data, unexpected error: yq could not evaluate`),
+			expErr: regexp.MustCompile(`Could not create YQ processor, could not parse yq expression`),
 		},
 
 		"Simple YQ execution should return the input.": {
@@ -54,6 +58,143 @@ values:
 			expResult: `a: b
 x: y`,
 		},
+
+		"Variables should be available on the YQ expression.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	input_data = <<EOT
+values:
+  a: b
+	EOT
+	vars       = {"extra": "it's \"quoted\""}
+	expression = ".values.extra = $extra | .values"
+}`,
+			expResult: `a: b
+extra: it's "quoted"`,
+		},
+
+		"Different input and output formats should convert the data.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	input_data    = "<config><name>test</name></config>"
+	input_format  = "xml"
+	output_format = "json"
+	expression    = ".config"
+}`,
+			expResult: `{
+  "name": "test"
+}`,
+		},
+
+		"Unsupported format combinations should fail.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	input_data    = "<config><name>test</name></config>"
+	input_format  = "xml"
+	output_format = "csv"
+	expression    = "."
+}`,
+			expErr: regexp.MustCompile(`Unsupported output options`),
+		},
+
+		"Output style options should be used on the result.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	input_data                = "a: {b: c}"
+	indent                    = 4
+	style                     = "double"
+	print_document_separators = true
+	expression                = "."
+}`,
+			expResult: `"a":
+    "b": "c"`,
+		},
+
+		"Wrapped scalars should keep the quotes.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	input_data    = "a: 'b'"
+	unwrap_scalar = false
+	expression    = ".a"
+}`,
+			expResult: `'b'`,
+		},
+
+		"YAML output style options with other outputs should fail.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	input_data    = "a: b"
+	output_format = "json"
+	style         = "flow"
+	expression    = "."
+}`,
+			expErr: regexp.MustCompile(`style can't be used with "json" output`),
+		},
+
+		"Unknown formats should fail.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	input_data   = "a = 1"
+	input_format = "toml"
+	expression   = "."
+}`,
+			expErr: regexp.MustCompile(`Attribute must be one of: yaml, json, xml, props, csv, tsv`),
+		},
+
+		"Invalid variable names should fail.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	input_data = "a: b"
+	vars       = {"not-valid": "something"}
+	expression = "."
+}`,
+			expErr: regexp.MustCompile(`invalid yq variable name "not-valid"`),
+		},
+
+		"Input values bigger than the max input size should fail.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	input          = {a = "bbbbbbbbbb"}
+	expression     = "."
+	max_input_size = 10
+}`,
+			expErr: regexp.MustCompile(`input data size \(18 bytes\) exceeds the max input size \(10 bytes\)`),
+		},
+
+		"Input values should be processed without encoding them.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	input        = {a = [1, "b"]}
+	input_format = "xml"
+	expression   = ".a"
+}`,
+			expResult:      "- 1\n- b",
+			expResults:     []string{"- 1\n- b"},
+			expResultValue: map[string]string{"result_value.#": "2", "result_value.0": "1", "result_value.1": "b"},
+		},
+
+		"Missing input should fail.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	expression = "."
+}`,
+			expErr: regexp.MustCompile("One of `input` or `input_data` must be set"),
+		},
+
+		"Multiple documents should be returned joined and independently.": {
+			config: `
+data "dataprocessor_yq" "test" {
+	input_data = <<EOT
+a: 1
+---
+b: 2
+EOT
+	expression = "."
+}`,
+			expResult:      "a: 1\nb: 2",
+			expResults:     []string{"a: 1", "b: 2"},
+			expResultValue: map[string]string{"result_value.#": "2", "result_value.0.a": "1", "result_value.1.b": "2"},
+		},
 	}
 
 	for name, test := range tests {
@@ -61,9 +202,19 @@ x: y`,
 			// Prepare non error checks.
 			var checks resource.TestCheckFunc
 			if test.expErr == nil {
-				checks = resource.ComposeAggregateTestCheckFunc(
+				checkFuncs := []resource.TestCheckFunc{
 					resource.TestCheckResourceAttr("data.dataprocessor_yq.test", "result", test.expResult),
-				)
+				}
+				if test.expResults != nil {
+					checkFuncs = append(checkFuncs, resource.TestCheckResourceAttr("data.dataprocessor_yq.test", "results.#", strconv.Itoa(len(test.expResults))))
+					for i, r := range test.expResults {
+						checkFuncs = append(checkFuncs, resource.TestCheckResourceAttr("data.dataprocessor_yq.test", fmt.Sprintf("results.%d", i), r))
+					}
+				}
+				for k, v := range test.expResultValue {
+					checkFuncs = append(checkFuncs, resource.TestCheckResourceAttr("data.dataprocessor_yq.test", k, v))
+				}
+				checks = resource.ComposeAggregateTestCheckFunc(checkFuncs...)
 			}
 
 			// Check.
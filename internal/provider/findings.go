@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor"
+)
+
+var findingType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"severity": types.StringType,
+	"summary":  types.StringType,
+	"detail":   types.StringType,
+	"path":     types.StringType,
+}}
+
+// findingsAttribute is the schema of the findings reported by the Go plugins.
+func findingsAttribute() schema.ListAttribute {
+	return schema.ListAttribute{
+		Description: "The findings reported by the plugin with `dataprocessor.AddFinding` (`severity`, `summary`, `detail` and `path`), every finding is also shown as a Terraform error or warning.",
+		Computed:    true,
+		ElementType: findingType,
+	}
+}
+
+// addFindings adds the findings reported by a plugin to the diagnostics, as errors or warnings of the attribute
+// that has the data the plugin processed.
+func addFindings(diags *diag.Diagnostics, attrPath path.Path, findings []dataprocessor.Finding) {
+	for _, f := range findings {
+		detail := f.Detail
+		switch {
+		case f.Path != "" && f.Detail != "":
+			detail = f.Path + ": " + f.Detail
+		case f.Path != "":
+			detail = f.Path
+		}
+
+		if f.Severity == dataprocessor.SeverityWarning {
+			diags.AddAttributeWarning(attrPath, f.Summary, detail)
+			continue
+		}
+		diags.AddAttributeError(attrPath, f.Summary, detail)
+	}
+}
+
+// findingsValue returns the findings reported by a plugin as a Terraform value.
+func findingsValue(ctx context.Context, findings []dataprocessor.Finding) (types.List, diag.Diagnostics) {
+	tfFindings := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		tfFindings = append(tfFindings, Finding{
+			Severity: types.StringValue(string(f.Severity)),
+			Summary:  types.StringValue(f.Summary),
+			Detail:   types.StringValue(f.Detail),
+			Path:     types.StringValue(f.Path),
+		})
+	}
+
+	return types.ListValueFrom(ctx, findingType, tfFindings)
+}
+
+// inputPath returns the path of the attribute that has the processor input.
+func inputPath(input types.Dynamic) path.Path {
+	if !input.IsNull() {
+		return path.Root("input")
+	}
+	return path.Root("input_data")
+}
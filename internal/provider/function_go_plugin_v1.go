@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+)
+
+var _ function.Function = &functionGoPluginV1{}
+
+func newFunctionGoPluginV1() function.Function {
+	return &functionGoPluginV1{}
+}
+
+type functionGoPluginV1 struct{}
+
+func (f *functionGoPluginV1) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "go_plugin_v1"
+}
+
+func (f *functionGoPluginV1) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Executes a Go plugin v1 processor.",
+		MarkdownDescription: `
+Executes a Go plugin v1 processor and returns the result, the same as the ` + "`dataprocessor_go_plugin_v1`" + ` data source ` + "`result`" + `.
+
+Functions don't use the provider configuration, so the plugins are always executed with the ` + "`safe`" + ` sandbox (they can't
+execute commands, access the network, access the filesystem or the environment), with a timeout of 10s and a max input
+data size of 10MiB. Use the ` + "`dataprocessor_go_plugin_v1`" + ` data source for plugins that need other settings.
+`,
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "plugin",
+				Description: "The Go plugin v1 source code. Uses the `func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error)` signature. The loaded plugins are reused between executions, the package level variables are not reset, so the plugins should not keep state on them.",
+			},
+			function.StringParameter{
+				Name:        "input_data",
+				Description: "The input raw data that will be processed by the plugin.",
+			},
+			function.MapParameter{
+				Name:           "vars",
+				Description:    "Variables that will be passed to the plugin execution.",
+				ElementType:    types.StringType,
+				AllowNullValue: true,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *functionGoPluginV1) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var plugin, inputData string
+	var vars map[string]string
+	resp.Error = req.Arguments.Get(ctx, &plugin, &inputData, &vars)
+	if resp.Error != nil {
+		return
+	}
+
+	err := checkMaxInputSize(inputData, functionMaxInputSize)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, "Invalid input data: "+err.Error())
+		return
+	}
+
+	// Functions can't be configured, so they can't bypass the sandbox policy of the provider.
+	p, err := process.NewGoPluginV1Processor(ctx, plugin, vars, process.GoPluginSandbox{Preset: process.SandboxPresetSafe})
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Could not create Go plugin v1 processor: "+sourceErrorMessage(err))
+		return
+	}
+	p = process.NewTimeoutProcessor(p, functionTimeout)
+
+	// Functions only have a result, the plugin output is only written on the provider debug logs.
+	output := &goPluginOutput{}
+	result, err := p.Process(output.context(ctx), inputData)
+	output.log(ctx)
+	if err != nil {
+		resp.Error = function.NewFuncError("Could not process input data: " + err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
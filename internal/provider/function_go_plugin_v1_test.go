@@ -0,0 +1,139 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+// TestAccFunctionGoPluginV1 will check a go plugin v1 function execution.
+func TestAccFunctionGoPluginV1(t *testing.T) {
+	tests := map[string]struct {
+		config    string
+		expResult string
+		expErr    *regexp.Regexp
+	}{
+		"An invalid plugin should fail.": {
+			config: `
+output "test" {
+	value = provider::dataprocessor::go_plugin_v1("package testplugin", "test", null)
+}`,
+			expErr: regexp.MustCompile(`Could not create Go plugin v1 processor`),
+		},
+
+		"A plugin should be executed with the safe sandbox.": {
+			config: `
+locals {
+	plugin = <<EOT
+package testplugin
+
+import (
+	"context"
+	"os/exec"
+)
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	out, err := exec.Command("echo", inputData).Output()
+	return string(out), err
+}
+EOT
+}
+
+output "test" {
+	value = provider::dataprocessor::go_plugin_v1(local.plugin, "test", null)
+}`,
+			expErr: regexp.MustCompile(`not allowed by the "safe" sandbox`),
+		},
+
+		"A plugin that takes more than the functions timeout should fail.": {
+			config: `
+locals {
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	for {
+	}
+}
+EOT
+}
+
+output "test" {
+	value = provider::dataprocessor::go_plugin_v1(local.plugin, "test", null)
+}`,
+			expErr: regexp.MustCompile(`processor timed out after 10s`),
+		},
+
+		"Input data bigger than the functions max input size should fail.": {
+			config: `
+locals {
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return inputData, nil
+}
+EOT
+}
+
+output "test" {
+	value = provider::dataprocessor::go_plugin_v1(local.plugin, join("", [for i in range(11) : format("%1048576s", "")]), null)
+}`,
+			expErr: regexp.MustCompile(`exceeds the max input size \(10485760 bytes\)`),
+		},
+
+		"A plugin should return the result with the variables.": {
+			config: `
+locals {
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return inputData + vars["suffix"], nil
+}
+EOT
+}
+
+output "test" {
+	value = provider::dataprocessor::go_plugin_v1(local.plugin, "this is a ", {suffix = "test"})
+}`,
+			expResult: "this is a test",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Prepare non error checks.
+			var checks resource.TestCheckFunc
+			if test.expErr == nil {
+				checks = resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("test", test.expResult),
+				)
+			}
+
+			// Check.
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				Steps: []resource.TestStep{
+					{
+						Config:      test.config,
+						Check:       checks,
+						ExpectError: test.expErr,
+					},
+				},
+			})
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+)
+
+var _ function.Function = &functionJQ{}
+
+func newFunctionJQ() function.Function {
+	return &functionJQ{}
+}
+
+type functionJQ struct{}
+
+func (f *functionJQ) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "jq"
+}
+
+func (f *functionJQ) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Executes a JQ expression on a value.",
+		MarkdownDescription: `
+Executes a JQ expression on a value and returns the result as a Terraform value, the same as the ` + "`dataprocessor_jq`" + `
+data source ` + "`result_value`" + `. If the expression emits multiple results, a list with all of them is returned.
+
+The expression can use the same functions as the ` + "`dataprocessor_jq`" + ` data source. Functions don't use the provider
+configuration, so they have a timeout of 10s and a max input size of 10MiB (measured encoded in JSON).
+`,
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "expression",
+				Description: "The JQ expression to be executed.",
+			},
+			function.DynamicParameter{
+				Name:           "input",
+				Description:    "The input value that will be processed with JQ, any Terraform value (e.g object, list, string...).",
+				AllowNullValue: true,
+			},
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (f *functionJQ) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var expression string
+	var input types.Dynamic
+	resp.Error = req.Arguments.Get(ctx, &expression, &input)
+	if resp.Error != nil {
+		return
+	}
+
+	value, err := inputValue(ctx, input)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, "Invalid input: "+err.Error())
+		return
+	}
+
+	inputData, err := inputString(value)
+	if err == nil {
+		err = checkMaxInputSize(inputData, functionMaxInputSize)
+	}
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, "Invalid input: "+err.Error())
+		return
+	}
+
+	jq, err := process.NewJQValueProcessor(ctx, expression, nil, process.JQOptions{})
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Could not create JQ processor: "+sourceErrorMessage(err))
+		return
+	}
+	jq = process.NewTimeoutValueProcessor(jq, functionTimeout)
+
+	res, err := jq.ProcessValue(ctx, value)
+	if err != nil {
+		resp.Error = function.NewFuncError("Could not process input: " + err.Error())
+		return
+	}
+
+	result, err := resultValue(ctx, res.Values)
+	if err != nil {
+		resp.Error = function.NewFuncError("Could not convert the result into a Terraform value: " + err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
@@ -0,0 +1,88 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+// TestAccFunctionJQ will check a jq function execution.
+func TestAccFunctionJQ(t *testing.T) {
+	tests := map[string]struct {
+		config    string
+		expResult string
+		expErr    *regexp.Regexp
+	}{
+		"An invalid JQ expression should fail.": {
+			config: `
+output "test" {
+	value = provider::dataprocessor::jq(".{", {})
+}`,
+			expErr: regexp.MustCompile(`could not parse JQ expression`),
+		},
+
+		"A JQ expression that takes more than the functions timeout should fail.": {
+			config: `
+output "test" {
+	value = provider::dataprocessor::jq("last(range(infinite))", {})
+}`,
+			expErr: regexp.MustCompile(`processor timed out after 10s`),
+		},
+
+		"Input bigger than the functions max input size should fail.": {
+			config: `
+output "test" {
+	value = provider::dataprocessor::jq(".", {a = join("", [for i in range(11) : format("%1048576s", "")])})
+}`,
+			expErr: regexp.MustCompile(`exceeds the max input size \(10485760 bytes\)`),
+		},
+
+		"A JQ expression should return the result as a Terraform value.": {
+			config: `
+output "test" {
+	value = provider::dataprocessor::jq("[.users[] | select(.admin) | .name]", {
+		users = [{name = "a", admin = true}, {name = "b", admin = false}]
+	})[0]
+}`,
+			expResult: "a",
+		},
+
+		"Multiple results should be returned as a list.": {
+			config: `
+output "test" {
+	value = join(",", provider::dataprocessor::jq(".[] | . * 2", [1, 2, 3]))
+}`,
+			expResult: "2,4,6",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Prepare non error checks.
+			var checks resource.TestCheckFunc
+			if test.expErr == nil {
+				checks = resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("test", test.expResult),
+				)
+			}
+
+			// Check.
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				Steps: []resource.TestStep{
+					{
+						Config:      test.config,
+						Check:       checks,
+						ExpectError: test.expErr,
+					},
+				},
+			})
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+)
+
+var _ function.Function = &functionYQ{}
+
+func newFunctionYQ() function.Function {
+	return &functionYQ{}
+}
+
+type functionYQ struct{}
+
+func (f *functionYQ) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "yq"
+}
+
+func (f *functionYQ) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Executes a YQ expression on YAML data.",
+		MarkdownDescription: `
+Executes a YQ expression on YAML data and returns the result as a Terraform value, the same as the ` + "`dataprocessor_yq`" + `
+data source ` + "`result_value`" + `. If the expression has multiple results (e.g multiple documents), a list with all of them is returned.
+
+Functions don't use the provider configuration, so they have a timeout of 10s and a max input data size of 10MiB.
+`,
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "expression",
+				Description: "The YQ expression to be executed.",
+			},
+			function.StringParameter{
+				Name:        "input_data",
+				Description: "The input YAML data that will be processed with YQ.",
+			},
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (f *functionYQ) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var expression, inputData string
+	resp.Error = req.Arguments.Get(ctx, &expression, &inputData)
+	if resp.Error != nil {
+		return
+	}
+
+	err := checkMaxInputSize(inputData, functionMaxInputSize)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, "Invalid input data: "+err.Error())
+		return
+	}
+
+	yq, err := process.NewYQProcessor(ctx, expression, nil, process.YQOptions{})
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Could not create YQ processor: "+sourceErrorMessage(err))
+		return
+	}
+	yq = process.NewTimeoutMultiResultProcessor(yq, functionTimeout)
+
+	res, err := yq.ProcessResults(ctx, inputData)
+	if err != nil {
+		resp.Error = function.NewFuncError("Could not process input data: " + err.Error())
+		return
+	}
+
+	result, err := resultValue(ctx, res.Values)
+	if err != nil {
+		resp.Error = function.NewFuncError("Could not convert the result into a Terraform value: " + err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
@@ -0,0 +1,78 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+// TestAccFunctionYQ will check a yq function execution.
+func TestAccFunctionYQ(t *testing.T) {
+	tests := map[string]struct {
+		config    string
+		expResult string
+		expErr    *regexp.Regexp
+	}{
+		"An invalid YQ expression should fail.": {
+			config: `
+output "test" {
+	value = provider::dataprocessor::yq(".{", "a: 1")
+}`,
+			expErr: regexp.MustCompile(`could not parse yq expression`),
+		},
+
+		"Input data bigger than the functions max input size should fail.": {
+			config: `
+output "test" {
+	value = provider::dataprocessor::yq(".", join("", [for i in range(11) : format("%1048576s", "")]))
+}`,
+			expErr: regexp.MustCompile(`exceeds the max input size \(10485760 bytes\)`),
+		},
+
+		"A YQ expression should return the result as a Terraform value.": {
+			config: `
+output "test" {
+	value = provider::dataprocessor::yq(".a.b", "a: {b: [x, y]}")[1]
+}`,
+			expResult: "y",
+		},
+
+		"Multiple documents should be returned as a list.": {
+			config: `
+output "test" {
+	value = join(",", provider::dataprocessor::yq(".a", "a: 1\n---\na: 2"))
+}`,
+			expResult: "1,2",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Prepare non error checks.
+			var checks resource.TestCheckFunc
+			if test.expErr == nil {
+				checks = resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("test", test.expResult),
+				)
+			}
+
+			// Check.
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				Steps: []resource.TestStep{
+					{
+						Config:      test.config,
+						Check:       checks,
+						ExpectError: test.expErr,
+					},
+				},
+			})
+		})
+	}
+}
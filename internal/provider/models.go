@@ -5,25 +5,131 @@ import (
 )
 
 type JQ struct {
-	Expression types.String            `tfsdk:"expression"`
-	InputData  types.String            `tfsdk:"input_data"`
-	Vars       map[string]types.String `tfsdk:"vars"`
-	Pretty     types.Bool              `tfsdk:"pretty"`
-	Result     types.String            `tfsdk:"result"`
-	ID         types.String            `tfsdk:"id"`
+	Expression   types.String            `tfsdk:"expression"`
+	InputData    types.String            `tfsdk:"input_data"`
+	Input        types.Dynamic           `tfsdk:"input"`
+	Vars         map[string]types.String `tfsdk:"vars"`
+	JSONVars     map[string]types.String `tfsdk:"json_vars"`
+	Pretty       types.Bool              `tfsdk:"pretty"`
+	Indent       types.Int64             `tfsdk:"indent"`
+	RawOutput    types.Bool              `tfsdk:"raw_output"`
+	JoinOutput   types.Bool              `tfsdk:"join_output"`
+	ASCIIOutput  types.Bool              `tfsdk:"ascii_output"`
+	Slurp        types.Bool              `tfsdk:"slurp"`
+	RawInput     types.Bool              `tfsdk:"raw_input"`
+	NullInput    types.Bool              `tfsdk:"null_input"`
+	Modules      map[string]types.String `tfsdk:"modules"`
+	MaxInputSize types.Int64             `tfsdk:"max_input_size"`
+	Timeout      types.String            `tfsdk:"timeout"`
+	Result       types.String            `tfsdk:"result"`
+	Results      types.List              `tfsdk:"results"`
+	ResultValue  types.Dynamic           `tfsdk:"result_value"`
+	ID           types.String            `tfsdk:"id"`
 }
 
 type YQ struct {
-	Expression types.String `tfsdk:"expression"`
-	InputData  types.String `tfsdk:"input_data"`
-	Result     types.String `tfsdk:"result"`
-	ID         types.String `tfsdk:"id"`
+	Expression              types.String            `tfsdk:"expression"`
+	InputData               types.String            `tfsdk:"input_data"`
+	Input                   types.Dynamic           `tfsdk:"input"`
+	Vars                    map[string]types.String `tfsdk:"vars"`
+	InputFormat             types.String            `tfsdk:"input_format"`
+	OutputFormat            types.String            `tfsdk:"output_format"`
+	Indent                  types.Int64             `tfsdk:"indent"`
+	UnwrapScalar            types.Bool              `tfsdk:"unwrap_scalar"`
+	PrintDocumentSeparators types.Bool              `tfsdk:"print_document_separators"`
+	Style                   types.String            `tfsdk:"style"`
+	MaxInputSize            types.Int64             `tfsdk:"max_input_size"`
+	Timeout                 types.String            `tfsdk:"timeout"`
+	Result                  types.String            `tfsdk:"result"`
+	Results                 types.List              `tfsdk:"results"`
+	ResultValue             types.Dynamic           `tfsdk:"result_value"`
+	ID                      types.String            `tfsdk:"id"`
 }
 
 type GoPluginV1 struct {
-	Plugin    types.String            `tfsdk:"plugin"`
-	InputData types.String            `tfsdk:"input_data"`
-	Vars      map[string]types.String `tfsdk:"vars"`
-	Result    types.String            `tfsdk:"result"`
-	ID        types.String            `tfsdk:"id"`
+	Plugin       types.String            `tfsdk:"plugin"`
+	PluginURL    types.String            `tfsdk:"plugin_url"`
+	PluginSHA256 types.String            `tfsdk:"plugin_sha256"`
+	InputData    types.String            `tfsdk:"input_data"`
+	Input        types.Dynamic           `tfsdk:"input"`
+	Vars         map[string]types.String `tfsdk:"vars"`
+	MaxInputSize types.Int64             `tfsdk:"max_input_size"`
+	Timeout      types.String            `tfsdk:"timeout"`
+	Sandbox      types.String            `tfsdk:"sandbox"`
+	SandboxAllow []types.String          `tfsdk:"sandbox_allow"`
+	Result       types.String            `tfsdk:"result"`
+	ResultValue  types.Dynamic           `tfsdk:"result_value"`
+	Stdout       types.String            `tfsdk:"stdout"`
+	Stderr       types.String            `tfsdk:"stderr"`
+	Findings     types.List              `tfsdk:"findings"`
+	ID           types.String            `tfsdk:"id"`
+}
+
+type GoPluginV2 struct {
+	Plugin       types.String            `tfsdk:"plugin"`
+	InputData    types.String            `tfsdk:"input_data"`
+	Input        types.Dynamic           `tfsdk:"input"`
+	Vars         map[string]types.String `tfsdk:"vars"`
+	JSONVars     map[string]types.String `tfsdk:"json_vars"`
+	MaxInputSize types.Int64             `tfsdk:"max_input_size"`
+	Timeout      types.String            `tfsdk:"timeout"`
+	Sandbox      types.String            `tfsdk:"sandbox"`
+	SandboxAllow []types.String          `tfsdk:"sandbox_allow"`
+	Result       types.String            `tfsdk:"result"`
+	ResultValue  types.Dynamic           `tfsdk:"result_value"`
+	Stdout       types.String            `tfsdk:"stdout"`
+	Stderr       types.String            `tfsdk:"stderr"`
+	Findings     types.List              `tfsdk:"findings"`
+	ID           types.String            `tfsdk:"id"`
+}
+
+type Pipeline struct {
+	InputData    types.String  `tfsdk:"input_data"`
+	Input        types.Dynamic `tfsdk:"input"`
+	Steps        types.List    `tfsdk:"step"`
+	MaxInputSize types.Int64   `tfsdk:"max_input_size"`
+	Timeout      types.String  `tfsdk:"timeout"`
+	Result       types.String  `tfsdk:"result"`
+	ResultValue  types.Dynamic `tfsdk:"result_value"`
+	Stdout       types.String  `tfsdk:"stdout"`
+	Stderr       types.String  `tfsdk:"stderr"`
+	Findings     types.List    `tfsdk:"findings"`
+	ID           types.String  `tfsdk:"id"`
+}
+
+type PipelineStep struct {
+	Type         types.String            `tfsdk:"type"`
+	Expression   types.String            `tfsdk:"expression"`
+	Plugin       types.String            `tfsdk:"plugin"`
+	Vars         map[string]types.String `tfsdk:"vars"`
+	RawOutput    types.Bool              `tfsdk:"raw_output"`
+	InputFormat  types.String            `tfsdk:"input_format"`
+	OutputFormat types.String            `tfsdk:"output_format"`
+}
+
+type Finding struct {
+	Severity types.String `tfsdk:"severity"`
+	Summary  types.String `tfsdk:"summary"`
+	Detail   types.String `tfsdk:"detail"`
+	Path     types.String `tfsdk:"path"`
+}
+
+// The resources have the same settings and results as the data sources, plus the triggers and the
+// provider settings that change the result.
+
+type JQResource struct {
+	JQ
+	Triggers         map[string]types.String `tfsdk:"triggers"`
+	ProviderSettings types.String            `tfsdk:"provider_settings"`
+}
+
+type YQResource struct {
+	YQ
+	Triggers map[string]types.String `tfsdk:"triggers"`
+}
+
+type GoPluginV1Resource struct {
+	GoPluginV1
+	Triggers         map[string]types.String `tfsdk:"triggers"`
+	ProviderSettings types.String            `tfsdk:"provider_settings"`
 }
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+)
+
+// stdoutAttribute is the schema of the standard output captured from the Go plugins.
+func stdoutAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: "The standard output written by the plugin (e.g `fmt.Println`), it's also written on the provider debug logs.",
+		Computed:    true,
+	}
+}
+
+// stderrAttribute is the schema of the standard error captured from the Go plugins.
+func stderrAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: "The standard error written by the plugin (e.g `log.Println` or `os.Stderr`), it's also written on the provider debug logs.",
+		Computed:    true,
+	}
+}
+
+// goPluginOutput captures the standard output and error of Go plugin executions.
+type goPluginOutput struct {
+	stdout lockedBuffer
+	stderr lockedBuffer
+}
+
+// context returns a context that makes the Go plugins write their output on o.
+func (o *goPluginOutput) context(ctx context.Context) context.Context {
+	return process.ContextWithGoPluginOutput(ctx, &o.stdout, &o.stderr)
+}
+
+// log writes the captured output on the provider debug logs.
+func (o *goPluginOutput) log(ctx context.Context) {
+	if stdout := o.stdout.String(); stdout != "" {
+		tflog.Debug(ctx, "Go plugin standard output", map[string]any{"stdout": stdout})
+	}
+	if stderr := o.stderr.String(); stderr != "" {
+		tflog.Debug(ctx, "Go plugin standard error", map[string]any{"stderr": stderr})
+	}
+}
+
+// lockedBuffer is a buffer that is safe to use concurrently, the plugins that time out can still be
+// writing when we read their output.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *lockedBuffer) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *lockedBuffer) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
@@ -2,22 +2,63 @@ package provider
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	tfprovider "github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/fetch"
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+	"github.com/slok/terraform-provider-dataprocessor/internal/provider/attributeutils"
+)
+
+var (
+	_ tfprovider.Provider              = &provider{}
+	_ tfprovider.ProviderWithFunctions = &provider{}
 )
 
-func New() tfsdk.Provider {
+func New() tfprovider.Provider {
 	return &provider{}
 }
 
 type provider struct {
-	configured bool
+	configured    bool
+	defaults      processorDefaults
+	pluginFetcher fetch.Fetcher
+}
+
+// processorDefaults are the provider level settings that the data sources and resources will use
+// when they don't set their own.
+type processorDefaults struct {
+	jqPretty        bool
+	jqLibraryPaths  []string
+	timeout         time.Duration
+	maxInputSize    int64
+	goPluginSandbox process.GoPluginSandbox
+}
+
+func (p *provider) Metadata(_ context.Context, _ tfprovider.MetadataRequest, resp *tfprovider.MetadataResponse) {
+	resp.TypeName = "dataprocessor"
 }
 
-// GetSchema returns the schema that the user must configure on the provider block.
-func (p *provider) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
-	return tfsdk.Schema{
+// Schema returns the schema that the user must configure on the provider block.
+func (p *provider) Schema(_ context.Context, _ tfprovider.SchemaRequest, resp *tfprovider.SchemaResponse) {
+	resp.Schema = schema.Schema{
 		Description: `
 The Data processor provider is used to process data in a simple and clean way inside terraform code
 to avoid HCL over-engineering
@@ -30,16 +71,70 @@ can be used afterwards as outputs or other terraform resources, providers and mo
 
 ## Terraform cloud
 
-The provider is portable and doesn't depend on any binary, its compatible with terraform cloud workers out of the box.`,
-		Attributes: map[string]tfsdk.Attribute{},
-	}, nil
+The provider is portable and doesn't depend on any binary, its compatible with terraform cloud workers out of the box.
+
+## Defaults
+
+The settings on the provider block are used as defaults by all the data sources and resources, a data source
+or resource can override them by setting its own value.
+
+## Resources
+
+The data sources execute the processors on every plan. The ` + "`dataprocessor_jq`" + `, ` + "`dataprocessor_yq`" + ` and
+` + "`dataprocessor_go_plugin_v1`" + ` resources have the same settings, but store the result in the state and only execute
+the processor again when its settings or ` + "`triggers`" + ` change.`,
+		Attributes: map[string]schema.Attribute{
+			"jq_pretty": schema.BoolAttribute{
+				Description: "Default value of the `pretty` attribute of the JQ data sources and resources. Defaults to `false`.",
+				Optional:    true,
+			},
+			"jq_library_paths": schema.ListAttribute{
+				Description: "Directories where the JQ modules imported by the JQ expressions (e.g `import \"lib\" as lib;`) are searched when they are not set on the data source `modules`, like jq `-L`.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Default maximum duration of a processor execution (e.g `30s`, `5m`). The timed out executions fail, but yq can't be stopped while it evaluates a document and keeps running in the background until it finishes. By default there is no timeout.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.Duration},
+			},
+			"max_input_size": schema.Int64Attribute{
+				Description: "Default maximum size in bytes of the input data that a processor accepts, the `input` values are measured encoded in JSON. By default there is no limit.",
+				Optional:    true,
+				Validators:  []validator.Int64{attributeutils.PositiveInt64},
+			},
+			"go_plugin_sandbox": schema.StringAttribute{
+				Description: "Default sandbox preset of the Go plugins. `unrestricted` exposes all the Go standard library, `safe` doesn't expose the packages and symbols that can execute commands, access the network, access the filesystem or the environment. Defaults to `unrestricted`.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.OneOf(sandboxPresets()...)},
+			},
+			"go_plugin_sandbox_allow": schema.ListAttribute{
+				Description: "Default Go standard library packages (e.g `net/http`) or symbols (e.g `os.WriteFile`) allowed to the Go plugins on top of the sandbox preset.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"plugin_cache_dir": schema.StringAttribute{
+				Description: "Directory where the Go plugins downloaded from URLs are cached. Defaults to the `terraform-provider-dataprocessor/plugins` directory inside the user cache directory.",
+				Optional:    true,
+				Validators:  []validator.String{attributeutils.NonEmptyString},
+			},
+		},
+	}
 }
 
 // Provider configuration.
-type providerData struct{}
+type providerData struct {
+	JQPretty             types.Bool     `tfsdk:"jq_pretty"`
+	JQLibraryPaths       []types.String `tfsdk:"jq_library_paths"`
+	Timeout              types.String   `tfsdk:"timeout"`
+	MaxInputSize         types.Int64    `tfsdk:"max_input_size"`
+	GoPluginSandbox      types.String   `tfsdk:"go_plugin_sandbox"`
+	GoPluginSandboxAllow []types.String `tfsdk:"go_plugin_sandbox_allow"`
+	PluginCacheDir       types.String   `tfsdk:"plugin_cache_dir"`
+}
 
 // This is like if it was our main entrypoint.
-func (p *provider) Configure(ctx context.Context, req tfsdk.ConfigureProviderRequest, resp *tfsdk.ConfigureProviderResponse) {
+func (p *provider) Configure(ctx context.Context, req tfprovider.ConfigureRequest, resp *tfprovider.ConfigureResponse) {
 	// Retrieve provider data from configuration.
 	var config providerData
 	diags := req.Config.Get(ctx, &config)
@@ -48,17 +143,339 @@ func (p *provider) Configure(ctx context.Context, req tfsdk.ConfigureProviderReq
 		return
 	}
 
+	defaults := processorDefaults{
+		jqPretty:       config.JQPretty.ValueBool(),
+		jqLibraryPaths: stringList(config.JQLibraryPaths),
+		maxInputSize:   config.MaxInputSize.ValueInt64(),
+		goPluginSandbox: process.GoPluginSandbox{
+			Preset: process.SandboxPreset(config.GoPluginSandbox.ValueString()),
+			Allow:  stringList(config.GoPluginSandboxAllow),
+		},
+	}
+
+	if !config.Timeout.IsNull() && !config.Timeout.IsUnknown() {
+		timeout, err := time.ParseDuration(config.Timeout.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid provider timeout", err.Error())
+			return
+		}
+		defaults.timeout = timeout
+	}
+
+	p.defaults = defaults
+	p.pluginFetcher = fetch.NewHTTPFetcher(&http.Client{Timeout: fetch.DefaultTimeout}, pluginCacheDir(config.PluginCacheDir))
 	p.configured = true
+
+	// The data sources and resources receive the configured provider.
+	resp.DataSourceData = p
+	resp.ResourceData = p
+}
+
+// pluginCacheDir returns the directory where the downloaded plugins are cached, if the user cache
+// directory can't be found, the plugins will not be cached.
+func pluginCacheDir(cacheDir types.String) string {
+	if !cacheDir.IsNull() && !cacheDir.IsUnknown() {
+		return cacheDir.ValueString()
+	}
+
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(userCacheDir, "terraform-provider-dataprocessor", "plugins")
+}
+
+// processorTimeout returns the timeout of the processor executions, the timeout can be overridden
+// by the data source, otherwise the provider default will be used.
+func (p provider) processorTimeout(timeout types.String) (time.Duration, error) {
+	if timeout.IsNull() || timeout.IsUnknown() {
+		return p.defaults.timeout, nil
+	}
+
+	return time.ParseDuration(timeout.ValueString())
+}
+
+// checkInputSize checks the input data is not bigger than the max input size, the max size can be
+// overridden by the data source, otherwise the provider default will be used.
+func (p provider) checkInputSize(inputData string, maxInputSize types.Int64) error {
+	max := p.defaults.maxInputSize
+	if !maxInputSize.IsNull() && !maxInputSize.IsUnknown() {
+		max = maxInputSize.ValueInt64()
+	}
+
+	return checkMaxInputSize(inputData, max)
+}
+
+// checkMaxInputSize checks the input data is not bigger than max, 0 means no limit.
+func checkMaxInputSize(inputData string, max int64) error {
+	if max > 0 && int64(len(inputData)) > max {
+		return fmt.Errorf("input data size (%d bytes) exceeds the max input size (%d bytes)", len(inputData), max)
+	}
+
+	return nil
+}
+
+// checkInputValueSize checks the input value is not bigger than the max input size, the size of the value is the
+// size of its encoded data, the same data the processors that only understand raw data receive.
+func (p provider) checkInputValueSize(input any, maxInputSize types.Int64) error {
+	inputData, err := inputString(input)
+	if err != nil {
+		return err
+	}
+
+	return p.checkInputSize(inputData, maxInputSize)
+}
+
+// goPluginSandbox returns the sandbox of the Go plugins, the sandbox preset and allow list can be
+// overridden by the data source, otherwise the provider defaults will be used.
+func (p provider) goPluginSandbox(preset types.String, allow []types.String) process.GoPluginSandbox {
+	sandbox := p.defaults.goPluginSandbox
+	if !preset.IsNull() && !preset.IsUnknown() {
+		sandbox.Preset = process.SandboxPreset(preset.ValueString())
+	}
+
+	if allow != nil {
+		sandbox.Allow = stringList(allow)
+	}
+
+	return sandbox
+}
+
+// decodeVars returns the variables of a processor, the JSON vars are decoded so they keep their type. If the
+// vars are not valid, the errors will be added to the diagnostics.
+func decodeVars(diags *diag.Diagnostics, vars, jsonVars map[string]types.String) map[string]any {
+	decoded := map[string]any{}
+	for k, v := range vars {
+		decoded[k] = v.ValueString()
+	}
+
+	for k, v := range jsonVars {
+		if _, ok := decoded[k]; ok {
+			diags.AddAttributeError(path.Root("json_vars").AtMapKey(k), "Duplicated variable", fmt.Sprintf("Variable %q is already set on vars.", k))
+			return nil
+		}
+
+		var jv any
+		err := json.Unmarshal([]byte(v.ValueString()), &jv)
+		if err != nil {
+			diags.AddAttributeError(path.Root("json_vars").AtMapKey(k), "Invalid JSON variable", err.Error())
+			return nil
+		}
+		decoded[k] = jv
+	}
+
+	return decoded
+}
+
+// contentID returns a stable ID of a processor execution, it's the hash of the processor type and the data that
+// determines the result (e.g the expression, the input and the vars), so the ID only changes when the result can change.
+func contentID(processorType string, data map[string]any) (string, error) {
+	// The maps are encoded with their keys sorted, so the same data always has the same encoding.
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(processorType))
+	h.Write([]byte{0})
+	h.Write(encoded)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// addProcessError adds the error of a processor execution to the diagnostics.
+func addProcessError(diags *diag.Diagnostics, processorName string, err error) {
+	var timeoutErr process.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		diags.AddError(processorName+" processor timed out", fmt.Sprintf("Could not process input data, %s.", timeoutErr))
+		return
+	}
+
+	diags.AddError("Error executing "+processorName+" processor", "Could not process input data, unexpected error: "+err.Error())
 }
 
-func (p *provider) GetResources(_ context.Context) (map[string]tfsdk.ResourceType, diag.Diagnostics) {
-	return map[string]tfsdk.ResourceType{}, nil
+// addProcessorCreateError adds the error of a processor creation to the diagnostics, if the error is at a known
+// position of the processor source, it's added on the source attribute (e.g `expression`) with the source line.
+// The errors of other sources (e.g JQ modules) have the source line but they are not added on the attribute.
+func addProcessorCreateError(diags *diag.Diagnostics, processorName string, srcPath path.Path, err error) {
+	var srcErr process.SourceError
+	if errors.As(err, &srcErr) {
+		if srcErr.Source != "" {
+			diags.AddError("Error creating "+processorName+" processor", "Could not create "+processorName+" processor, "+sourceErrorMessage(err))
+			return
+		}
+		diags.AddAttributeError(srcPath, "Error creating "+processorName+" processor", "Could not create "+processorName+" processor, "+sourceErrorMessage(err))
+		return
+	}
+
+	diags.AddError("Error creating "+processorName+" processor", "Could not create "+processorName+" processor, unexpected error: "+err.Error())
+}
+
+// addGoPluginLoadError adds the error of a Go plugin load to the diagnostics.
+func addGoPluginLoadError(diags *diag.Diagnostics, processorName string, pluginPath path.Path, err error) {
+	var sandboxErr process.SandboxError
+	if errors.As(err, &sandboxErr) {
+		diags.AddAttributeError(pluginPath, "Forbidden Go plugin package", fmt.Sprintf("The plugin imports the %q package, that is not allowed by the %q sandbox.", sandboxErr.Package, sandboxErr.Preset))
+		return
+	}
+
+	addProcessorCreateError(diags, processorName, pluginPath, err)
+}
+
+// sourceErrorMessage returns the message of err, if the error is at a known position of the processor source,
+// the message has the source line pointing to the error.
+func sourceErrorMessage(err error) string {
+	var srcErr process.SourceError
+	if !errors.As(err, &srcErr) {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("%s:\n\n%s", err, srcErr.Snippet())
+}
+
+// validateInput validates the input is set exactly once, either as raw data with `input_data` or as a value
+// with `input`. If the input is optional, it can be missing.
+func validateInput(diags *diag.Diagnostics, inputData types.String, input types.Dynamic, optional bool) {
+	// We can't know yet.
+	if inputData.IsUnknown() || input.IsUnknown() {
+		return
+	}
+
+	switch {
+	case !inputData.IsNull() && !input.IsNull():
+		diags.AddAttributeError(path.Root("input"), "Conflicting input", "Only one of `input` or `input_data` can be set.")
+	case inputData.IsNull() && input.IsNull() && !optional:
+		diags.AddAttributeError(path.Root("input_data"), "Missing input data", "One of `input` or `input_data` must be set.")
+	}
+}
+
+// validateGoPluginSource validates the Go plugin source is set exactly once, either inline with the source code
+// or with an URL pinned to a checksum.
+func validateGoPluginSource(diags *diag.Diagnostics, plugin, pluginURL, pluginSHA256 types.String) {
+	// We can't know yet.
+	if plugin.IsUnknown() || pluginURL.IsUnknown() || pluginSHA256.IsUnknown() {
+		return
+	}
+
+	switch {
+	case plugin.IsNull() && pluginURL.IsNull():
+		diags.AddAttributeError(path.Root("plugin"), "Missing Go plugin", "One of `plugin` or `plugin_url` must be set.")
+	case !plugin.IsNull() && !pluginURL.IsNull():
+		diags.AddAttributeError(path.Root("plugin_url"), "Conflicting Go plugin source", "Only one of `plugin` or `plugin_url` can be set.")
+	case !pluginURL.IsNull() && pluginSHA256.IsNull():
+		diags.AddAttributeError(path.Root("plugin_sha256"), "Missing Go plugin checksum", "`plugin_sha256` is required when `plugin_url` is set.")
+	case pluginURL.IsNull() && !pluginSHA256.IsNull():
+		diags.AddAttributeError(path.Root("plugin_sha256"), "Unused Go plugin checksum", "`plugin_sha256` can only be set with `plugin_url`.")
+	}
+}
+
+// goPluginSource returns the Go plugin source code, if the plugin is set by URL, it will be downloaded
+// and verified against the checksum.
+func (p provider) goPluginSource(ctx context.Context, plugin, pluginURL, pluginSHA256 types.String) (string, error) {
+	if pluginURL.IsNull() {
+		return plugin.ValueString(), nil
+	}
+
+	return p.pluginFetcher.Fetch(ctx, pluginURL.ValueString(), pluginSHA256.ValueString())
+}
+
+// goPluginSourcePath returns the path of the attribute that has the Go plugin source.
+func goPluginSourcePath(pluginURL types.String) path.Path {
+	if pluginURL.IsNull() {
+		return path.Root("plugin")
+	}
+
+	return path.Root("plugin_url")
 }
 
-func (p *provider) GetDataSources(_ context.Context) (map[string]tfsdk.DataSourceType, diag.Diagnostics) {
-	return map[string]tfsdk.DataSourceType{
-		"dataprocessor_jq":           dataSourceJQType{},
-		"dataprocessor_yq":           dataSourceYQType{},
-		"dataprocessor_go_plugin_v1": dataSourceGoPluginV1Type{},
-	}, nil
+// addGoPluginSourceError adds the error of a Go plugin download to the diagnostics.
+func addGoPluginSourceError(diags *diag.Diagnostics, err error) {
+	var checksumErr fetch.ChecksumMismatchError
+	if errors.As(err, &checksumErr) {
+		diags.AddAttributeError(path.Root("plugin_sha256"), "Go plugin checksum mismatch", fmt.Sprintf("The plugin downloaded from %q has the %q sha256 checksum, expected %q, refusing to run it.", checksumErr.URL, checksumErr.Got, checksumErr.Expected))
+		return
+	}
+
+	diags.AddAttributeError(path.Root("plugin_url"), "Error downloading Go plugin", "Could not download Go plugin, unexpected error: "+err.Error())
+}
+
+func sandboxPresets() []string {
+	presets := []string{}
+	for _, p := range process.SandboxPresets {
+		presets = append(presets, string(p))
+	}
+	return presets
+}
+
+func yqFormats() []string {
+	formats := []string{}
+	for _, f := range process.YQFormats {
+		formats = append(formats, string(f))
+	}
+	return formats
+}
+
+func yqStyles() []string {
+	styles := []string{}
+	for _, s := range process.YQStyles {
+		styles = append(styles, string(s))
+	}
+	return styles
+}
+
+func stringList(l []types.String) []string {
+	if l == nil {
+		return nil
+	}
+
+	sl := make([]string, 0, len(l))
+	for _, s := range l {
+		sl = append(sl, s.ValueString())
+	}
+	return sl
+}
+
+func stringMap(m map[string]types.String) map[string]string {
+	sm := make(map[string]string, len(m))
+	for k, v := range m {
+		sm[k] = v.ValueString()
+	}
+	return sm
+}
+
+func (p *provider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		newResourceJQ,
+		newResourceYQ,
+		newResourceGoPluginV1,
+	}
+}
+
+func (p *provider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{
+		newDataSourceJQ,
+		newDataSourceYQ,
+		newDataSourceGoPluginV1,
+		newDataSourceGoPluginV2,
+		newDataSourcePipeline,
+	}
+}
+
+const (
+	// functionTimeout is the maximum duration of the provider functions executions, functions can't be configured
+	// so they have a fixed timeout to not block Terraform forever.
+	functionTimeout = 10 * time.Second
+	// functionMaxInputSize is the maximum size in bytes of the provider functions input data, the input values are
+	// measured encoded in JSON.
+	functionMaxInputSize = 10 * 1024 * 1024
+)
+
+func (p *provider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		newFunctionJQ,
+		newFunctionYQ,
+		newFunctionGoPluginV1,
+	}
 }
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+
+	"github.com/slok/terraform-provider-dataprocessor/internal/process"
+)
+
+func newResourceGoPluginV1() resource.Resource {
+	return &processorResource[GoPluginV1Resource]{
+		name:          "go_plugin_v1",
+		newDataSource: newDataSourceGoPluginV1,
+		description: `
+Executes a Go plugin v1 processor and stores the result in the state.
+
+Unlike the ` + "`dataprocessor_go_plugin_v1`" + ` data source, that executes the plugin on every plan, the plugin is only executed
+again when the plugin, the input, the vars or the sandbox (including the provider ` + "`go_plugin_sandbox`" + ` and
+` + "`go_plugin_sandbox_allow`" + `) change, or when any of the ` + "`triggers`" + ` change, like ` + "`null_resource`" + `. This is useful
+for slow plugins or plugins that access external state (e.g the filesystem or the network). The ` + "`timeout`" + ` and
+` + "`max_input_size`" + ` limits can be changed without executing it again.
+
+The plugin API, the remote plugins and the sandbox are the same as the ` + "`dataprocessor_go_plugin_v1`" + ` data source ones.
+`,
+		validate: func(diags *diag.Diagnostics, tf GoPluginV1Resource) { validateGoPluginV1(diags, tf.GoPluginV1) },
+		process: func(ctx context.Context, p provider, diags *diag.Diagnostics, tf *GoPluginV1Resource) {
+			p.processGoPluginV1(ctx, diags, &tf.GoPluginV1)
+		},
+		providerSettings: func(p provider, tf GoPluginV1Resource) (map[string]any, bool) {
+			if tf.Sandbox.IsUnknown() {
+				return nil, false
+			}
+			for _, a := range tf.SandboxAllow {
+				if a.IsUnknown() {
+					return nil, false
+				}
+			}
+
+			sandbox := p.goPluginSandbox(tf.Sandbox, tf.SandboxAllow)
+			if sandbox.Preset == "" {
+				sandbox.Preset = process.SandboxPresetUnrestricted
+			}
+			allow := sandbox.Allow
+			if allow == nil {
+				allow = []string{}
+			}
+
+			return map[string]any{"go_plugin_sandbox": sandbox.Preset, "go_plugin_sandbox_allow": allow}, true
+		},
+	}
+}
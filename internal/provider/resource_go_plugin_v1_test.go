@@ -0,0 +1,115 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+// TestAccResourceGoPluginV1 will check a go plugin v1 resource execution.
+func TestAccResourceGoPluginV1(t *testing.T) {
+	tests := map[string]struct {
+		config    string
+		expResult string
+		expErr    *regexp.Regexp
+	}{
+		"An invalid plugin should fail.": {
+			config: `
+resource "dataprocessor_go_plugin_v1" "test" {
+	input_data = "{}"
+	plugin = <<EOT
+package testplugin
+
+func 
+	EOT
+}`,
+			expErr: regexp.MustCompile(`Could not create Go plugin v1 processor, could not load`),
+		},
+
+		"The plugin should be executed and the result stored.": {
+			config: `
+resource "dataprocessor_go_plugin_v1" "test" {
+	input_data = "hello"
+	vars       = {"name": "world"}
+	triggers   = {"version": "1"}
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return inputData + " " + vars["name"], nil
+}
+	EOT
+}`,
+			expResult: "hello world",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Prepare non error checks.
+			var checks resource.TestCheckFunc
+			if test.expErr == nil {
+				checks = resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dataprocessor_go_plugin_v1.test", "result", test.expResult),
+				)
+			}
+
+			// Check.
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config:      test.config,
+						Check:       checks,
+						ExpectError: test.expErr,
+					},
+				},
+			})
+		})
+	}
+}
+
+// TestAccResourceGoPluginV1ProviderSettings will check the go plugin v1 resource is executed again when the
+// provider sandbox changes.
+func TestAccResourceGoPluginV1ProviderSettings(t *testing.T) {
+	config := `
+resource "dataprocessor_go_plugin_v1" "test" {
+	input_data = "hello"
+	plugin = <<EOT
+package testplugin
+
+import "context"
+
+func ProcessorPluginV1(ctx context.Context, inputData string, vars map[string]string) (string, error) {
+	return inputData, nil
+}
+	EOT
+}`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("dataprocessor_go_plugin_v1.test", "provider_settings", `{"go_plugin_sandbox":"unrestricted","go_plugin_sandbox_allow":[]}`),
+			},
+			{
+				Config: `
+provider "dataprocessor" {
+	go_plugin_sandbox = "safe"
+}
+` + config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction("dataprocessor_go_plugin_v1.test", plancheck.ResourceActionReplace)},
+				},
+				Check: resource.TestCheckResourceAttr("dataprocessor_go_plugin_v1.test", "provider_settings", `{"go_plugin_sandbox":"safe","go_plugin_sandbox_allow":[]}`),
+			},
+		},
+	})
+}
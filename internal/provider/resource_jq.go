@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func newResourceJQ() resource.Resource {
+	return &processorResource[JQResource]{
+		name:          "jq",
+		newDataSource: newDataSourceJQ,
+		description: `
+Executes a JQ expression and stores the result in the state.
+
+Unlike the ` + "`dataprocessor_jq`" + ` data source, that executes the expression on every plan, the expression is only executed
+again when the expression, the input, the vars or any other JQ setting change (including the provider ` + "`jq_pretty`" + ` and
+` + "`jq_library_paths`" + `), or when any of the ` + "`triggers`" + ` change, like ` + "`null_resource`" + `. The ` + "`timeout`" + ` and
+` + "`max_input_size`" + ` limits can be changed without executing it again.
+
+The settings, the input data and the JQ functions are the same as the ` + "`dataprocessor_jq`" + ` data source ones.
+`,
+		validate: func(diags *diag.Diagnostics, tf JQResource) { validateJQ(diags, tf.JQ) },
+		process: func(ctx context.Context, p provider, diags *diag.Diagnostics, tf *JQResource) {
+			p.processJQ(ctx, diags, &tf.JQ)
+		},
+		providerSettings: func(p provider, tf JQResource) (map[string]any, bool) {
+			if tf.Pretty.IsUnknown() {
+				return nil, false
+			}
+
+			pretty := p.defaults.jqPretty
+			if !tf.Pretty.IsNull() {
+				pretty = tf.Pretty.ValueBool()
+			}
+			libraryPaths := p.defaults.jqLibraryPaths
+			if libraryPaths == nil {
+				libraryPaths = []string{}
+			}
+
+			return map[string]any{"jq_pretty": pretty, "jq_library_paths": libraryPaths}, true
+		},
+	}
+}
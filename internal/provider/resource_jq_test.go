@@ -0,0 +1,189 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+// TestAccResourceJQ will check a jq resource execution.
+func TestAccResourceJQ(t *testing.T) {
+	tests := map[string]struct {
+		config         string
+		expResult      string
+		expResultValue map[string]string
+		expErr         *regexp.Regexp
+	}{
+		"An invalid JQ expression should fail.": {
+			config: `
+resource "dataprocessor_jq" "test" {
+	input_data = "{}"
+	expression = ".|()ASd-sda?"
+}`,
+			expErr: regexp.MustCompile(`Could not create JQ processor, could not parse JQ.*`),
+		},
+
+		"Having input data and input should fail.": {
+			config: `
+resource "dataprocessor_jq" "test" {
+	input_data = "{}"
+	input      = {"a": "b"}
+	expression = "."
+}`,
+			expErr: regexp.MustCompile(`Conflicting input`),
+		},
+
+		"The JQ expression should be executed and the result stored.": {
+			config: `
+resource "dataprocessor_jq" "test" {
+	input      = {"a": {"b": [1, "c", true]}}
+	expression = ".a"
+	triggers   = {"version": "1"}
+}`,
+			expResult:      `{"b":[1,"c",true]}`,
+			expResultValue: map[string]string{"result_value.b.#": "3", "result_value.b.0": "1", "result_value.b.1": "c", "result_value.b.2": "true"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Prepare non error checks.
+			var checks resource.TestCheckFunc
+			if test.expErr == nil {
+				checkFuncs := []resource.TestCheckFunc{
+					resource.TestCheckResourceAttr("dataprocessor_jq.test", "result", test.expResult),
+				}
+				for k, v := range test.expResultValue {
+					checkFuncs = append(checkFuncs, resource.TestCheckResourceAttr("dataprocessor_jq.test", k, v))
+				}
+				checks = resource.ComposeAggregateTestCheckFunc(checkFuncs...)
+			}
+
+			// Check.
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config:      test.config,
+						Check:       checks,
+						ExpectError: test.expErr,
+					},
+				},
+			})
+		})
+	}
+}
+
+// TestAccResourceJQChanges will check the jq resource is only executed again when its settings change.
+func TestAccResourceJQChanges(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "dataprocessor_jq" "test" {
+	input_data = "{\"a\": 1}"
+	expression = ".a"
+	triggers   = {"version": "1"}
+}`,
+				Check: resource.TestCheckResourceAttr("dataprocessor_jq.test", "result", "1"),
+			},
+			{
+				// Same settings, nothing to do.
+				Config: `
+resource "dataprocessor_jq" "test" {
+	input_data = "{\"a\": 1}"
+	expression = ".a"
+	triggers   = {"version": "1"}
+}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction("dataprocessor_jq.test", plancheck.ResourceActionNoop)},
+				},
+			},
+			{
+				// The limits are updated without executing the expression.
+				Config: `
+resource "dataprocessor_jq" "test" {
+	input_data = "{\"a\": 1}"
+	expression = ".a"
+	triggers   = {"version": "1"}
+	timeout    = "30s"
+}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction("dataprocessor_jq.test", plancheck.ResourceActionUpdate)},
+				},
+				Check: resource.TestCheckResourceAttr("dataprocessor_jq.test", "result", "1"),
+			},
+			{
+				// The triggers execute the expression again.
+				Config: `
+resource "dataprocessor_jq" "test" {
+	input_data = "{\"a\": 1}"
+	expression = ".a"
+	triggers   = {"version": "2"}
+	timeout    = "30s"
+}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction("dataprocessor_jq.test", plancheck.ResourceActionReplace)},
+				},
+			},
+			{
+				// The expression executes it again.
+				Config: `
+resource "dataprocessor_jq" "test" {
+	input_data = "{\"a\": 1}"
+	expression = ".a + 1"
+	triggers   = {"version": "2"}
+	timeout    = "30s"
+}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction("dataprocessor_jq.test", plancheck.ResourceActionReplace)},
+				},
+				Check: resource.TestCheckResourceAttr("dataprocessor_jq.test", "result", "2"),
+			},
+			{
+				// The provider settings that don't change the result don't execute it again.
+				Config: `
+provider "dataprocessor" {
+	timeout = "1m"
+}
+
+resource "dataprocessor_jq" "test" {
+	input_data = "{\"a\": 1}"
+	expression = ".a + 1"
+	triggers   = {"version": "2"}
+	timeout    = "30s"
+}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction("dataprocessor_jq.test", plancheck.ResourceActionNoop)},
+				},
+			},
+			{
+				// The provider settings that change the result execute it again.
+				Config: `
+provider "dataprocessor" {
+	timeout   = "1m"
+	jq_pretty = true
+}
+
+resource "dataprocessor_jq" "test" {
+	input_data = "{\"a\": 1}"
+	expression = "{b: (.a + 1)}"
+	triggers   = {"version": "2"}
+	timeout    = "30s"
+}`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction("dataprocessor_jq.test", plancheck.ResourceActionReplace)},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("dataprocessor_jq.test", "result", "{\n  \"b\": 2\n}"),
+					resource.TestCheckResourceAttr("dataprocessor_jq.test", "provider_settings", `{"jq_library_paths":[],"jq_pretty":true}`),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithConfigure      = &processorResource[JQResource]{}
+	_ resource.ResourceWithValidateConfig = &processorResource[JQResource]{}
+	_ resource.ResourceWithModifyPlan     = &processorResource[JQResource]{}
+)
+
+// processorResource is a managed resource that executes a processor and stores the result in the state, T is
+// the resource model. The processor is only executed when the resource is created, the settings that change the
+// result (including the provider ones) replace the resource.
+type processorResource[T any] struct {
+	p provider
+
+	// name is the resource type name without the provider prefix (e.g `jq`).
+	name          string
+	description   string
+	newDataSource func() datasource.DataSource
+	validate      func(diags *diag.Diagnostics, tf T)
+	process       func(ctx context.Context, p provider, diags *diag.Diagnostics, tf *T)
+	// providerSettings returns the provider settings that change the result of the processor, it returns false
+	// if they can't be known yet. Nil if the processor doesn't use any provider setting.
+	providerSettings func(p provider, tf T) (settings map[string]any, known bool)
+}
+
+func (r *processorResource[T]) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + r.name
+}
+
+func (r *processorResource[T]) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	var ds datasource.SchemaResponse
+	r.newDataSource().Schema(ctx, datasource.SchemaRequest{}, &ds)
+
+	resp.Schema = processorResourceSchema(ds.Schema, r.description, r.providerSettings != nil)
+}
+
+func (r *processorResource[T]) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// The provider data is not set until the provider has been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.p = *req.ProviderData.(*provider)
+}
+
+func (r *processorResource[T]) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var tf T
+	diags := req.Config.Get(ctx, &tf)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.validate(&resp.Diagnostics, tf)
+}
+
+// ModifyPlan plans the provider settings used by the processor, the resource is replaced when they change so
+// the result is not outdated.
+func (r *processorResource[T]) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy.
+	if r.providerSettings == nil || !r.p.configured || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var tf T
+	diags := req.Plan.Get(ctx, &tf)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	settings, known := r.providerSettings(r.p, tf)
+	if !known {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("provider_settings"), types.StringUnknown())...)
+		return
+	}
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not encode the provider settings", err.Error())
+		return
+	}
+	planned := types.StringValue(string(encoded))
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("provider_settings"), planned)...)
+
+	// New resources are executed anyway.
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var current types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("provider_settings"), &current)...)
+	if !current.Equal(planned) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("provider_settings"))
+	}
+}
+
+func (r *processorResource[T]) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError("Provider not configured", "The provider hasn't been configured before apply.")
+		return
+	}
+
+	// Retrieve values.
+	var tf T
+	diags := req.Plan.Get(ctx, &tf)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.process(ctx, r.p, &resp.Diagnostics, &tf)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, tf)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *processorResource[T]) Read(_ context.Context, _ resource.ReadRequest, _ *resource.ReadResponse) {
+	// The result only depends on the settings, there is nothing to refresh.
+}
+
+func (r *processorResource[T]) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Only the settings that don't change the result can be updated, the plan already has the previous results.
+	var tf T
+	diags := req.Plan.Get(ctx, &tf)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, tf)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *processorResource[T]) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+	// Nothing to delete, the resource is removed from the state.
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"fmt"
+
+	dsschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/dynamicplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// inPlaceAttributes are the processor settings that don't change the result, so they can be
+// updated without executing the processor again.
+var inPlaceAttributes = map[string]bool{
+	"timeout":        true,
+	"max_input_size": true,
+}
+
+// processorResourceSchema returns the schema of a processor resource from the schema of its data source, so
+// both have the same settings. Changing any setting that changes the result (or the triggers) replaces the
+// resource, and the computed results are kept on the state until then.
+//
+// The resources of the processors that use provider settings have them on the `provider_settings` attribute,
+// the replacement when they change is planned by the resource.
+func processorResourceSchema(ds dsschema.Schema, description string, providerSettings bool) schema.Schema {
+	attrs := map[string]schema.Attribute{
+		"triggers": schema.MapAttribute{
+			Description:   "Arbitrary values that will execute the processor again when they change (e.g a version or a timestamp).",
+			Optional:      true,
+			ElementType:   types.StringType,
+			PlanModifiers: []planmodifier.Map{mapplanmodifier.RequiresReplace()},
+		},
+	}
+	if providerSettings {
+		attrs["provider_settings"] = schema.StringAttribute{
+			Description: "The provider settings used by the processor that change the result, encoded in JSON. The processor is executed again when they change.",
+			Computed:    true,
+		}
+	}
+	for name, a := range ds.Attributes {
+		attrs[name] = processorResourceAttribute(name, a)
+	}
+
+	return schema.Schema{
+		Description: description,
+		Attributes:  attrs,
+	}
+}
+
+func processorResourceAttribute(name string, a dsschema.Attribute) schema.Attribute {
+	computed := a.IsComputed() && !a.IsOptional()
+	replace := !computed && !inPlaceAttributes[name]
+
+	switch a := a.(type) {
+	case dsschema.StringAttribute:
+		ra := schema.StringAttribute{Description: a.Description, Required: a.Required, Optional: a.Optional, Computed: a.Computed, Validators: a.Validators}
+		switch {
+		case computed:
+			ra.PlanModifiers = []planmodifier.String{stringplanmodifier.UseStateForUnknown()}
+		case replace:
+			ra.PlanModifiers = []planmodifier.String{stringplanmodifier.RequiresReplace()}
+		}
+		return ra
+
+	case dsschema.BoolAttribute:
+		ra := schema.BoolAttribute{Description: a.Description, Required: a.Required, Optional: a.Optional, Computed: a.Computed, Validators: a.Validators}
+		switch {
+		case computed:
+			ra.PlanModifiers = []planmodifier.Bool{boolplanmodifier.UseStateForUnknown()}
+		case replace:
+			ra.PlanModifiers = []planmodifier.Bool{boolplanmodifier.RequiresReplace()}
+		}
+		return ra
+
+	case dsschema.Int64Attribute:
+		ra := schema.Int64Attribute{Description: a.Description, Required: a.Required, Optional: a.Optional, Computed: a.Computed, Validators: a.Validators}
+		switch {
+		case computed:
+			ra.PlanModifiers = []planmodifier.Int64{int64planmodifier.UseStateForUnknown()}
+		case replace:
+			ra.PlanModifiers = []planmodifier.Int64{int64planmodifier.RequiresReplace()}
+		}
+		return ra
+
+	case dsschema.ListAttribute:
+		ra := schema.ListAttribute{Description: a.Description, Required: a.Required, Optional: a.Optional, Computed: a.Computed, ElementType: a.ElementType, Validators: a.Validators}
+		switch {
+		case computed:
+			ra.PlanModifiers = []planmodifier.List{listplanmodifier.UseStateForUnknown()}
+		case replace:
+			ra.PlanModifiers = []planmodifier.List{listplanmodifier.RequiresReplace()}
+		}
+		return ra
+
+	case dsschema.MapAttribute:
+		ra := schema.MapAttribute{Description: a.Description, Required: a.Required, Optional: a.Optional, Computed: a.Computed, ElementType: a.ElementType, Validators: a.Validators}
+		switch {
+		case computed:
+			ra.PlanModifiers = []planmodifier.Map{mapplanmodifier.UseStateForUnknown()}
+		case replace:
+			ra.PlanModifiers = []planmodifier.Map{mapplanmodifier.RequiresReplace()}
+		}
+		return ra
+
+	case dsschema.DynamicAttribute:
+		ra := schema.DynamicAttribute{Description: a.Description, Required: a.Required, Optional: a.Optional, Computed: a.Computed, Validators: a.Validators}
+		switch {
+		case computed:
+			ra.PlanModifiers = []planmodifier.Dynamic{dynamicplanmodifier.UseStateForUnknown()}
+		case replace:
+			ra.PlanModifiers = []planmodifier.Dynamic{dynamicplanmodifier.RequiresReplace()}
+		}
+		return ra
+	}
+
+	// The data sources schemas are static, this can only happen if a new attribute type is added.
+	panic(fmt.Sprintf("unsupported %q attribute type %T on processor resource", name, a))
+}
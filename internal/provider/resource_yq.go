@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+func newResourceYQ() resource.Resource {
+	return &processorResource[YQResource]{
+		name:          "yq",
+		newDataSource: newDataSourceYQ,
+		description: `
+Executes a YQ expression and stores the result in the state.
+
+Unlike the ` + "`dataprocessor_yq`" + ` data source, that executes the expression on every plan, the expression is only executed
+again when the expression, the input, the vars or any other YQ setting change, or when any of the ` + "`triggers`" + ` change, like
+` + "`null_resource`" + `. The ` + "`timeout`" + ` and ` + "`max_input_size`" + ` limits can be changed without executing it again.
+
+The settings and the input and output formats are the same as the ` + "`dataprocessor_yq`" + ` data source ones.
+`,
+		validate: func(diags *diag.Diagnostics, tf YQResource) { validateYQ(diags, tf.YQ) },
+		process: func(ctx context.Context, p provider, diags *diag.Diagnostics, tf *YQResource) {
+			p.processYQ(ctx, diags, &tf.YQ)
+		},
+	}
+}
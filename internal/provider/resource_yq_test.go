@@ -0,0 +1,70 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// TestAccResourceYQ will check a yq resource execution.
+func TestAccResourceYQ(t *testing.T) {
+	tests := map[string]struct {
+		config         string
+		expResult      string
+		expResultValue map[string]string
+		expErr         *regexp.Regexp
+	}{
+		"An invalid YQ expression should fail.": {
+			config: `
+resource "dataprocessor_yq" "test" {
+	input_data = "a: b"
+	expression = ".|()ASd-sda?"
+}`,
+			expErr: regexp.MustCompile(`Could not create YQ processor, could not parse yq expression`),
+		},
+
+		"The YQ expression should be executed and the result stored.": {
+			config: `
+resource "dataprocessor_yq" "test" {
+	input_data = <<EOT
+a:
+  b: [1, c]
+EOT
+	expression = ".a"
+	triggers   = {"version": "1"}
+}`,
+			expResult:      "b: [1, c]",
+			expResultValue: map[string]string{"result_value.b.#": "2", "result_value.b.0": "1", "result_value.b.1": "c"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Prepare non error checks.
+			var checks resource.TestCheckFunc
+			if test.expErr == nil {
+				checkFuncs := []resource.TestCheckFunc{
+					resource.TestCheckResourceAttr("dataprocessor_yq.test", "result", test.expResult),
+				}
+				for k, v := range test.expResultValue {
+					checkFuncs = append(checkFuncs, resource.TestCheckResourceAttr("dataprocessor_yq.test", k, v))
+				}
+				checks = resource.ComposeAggregateTestCheckFunc(checkFuncs...)
+			}
+
+			// Check.
+			resource.Test(t, resource.TestCase{
+				PreCheck:                 func() { testAccPreCheck(t) },
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config:      test.config,
+						Check:       checks,
+						ExpectError: test.expErr,
+					},
+				},
+			})
+		})
+	}
+}
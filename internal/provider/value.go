@@ -0,0 +1,224 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// resultValue returns the dynamic value of the processor results, a single result is returned as it is
+// and multiple results are returned as a tuple.
+func resultValue(ctx context.Context, values []any) (types.Dynamic, error) {
+	var value any = values
+	if len(values) == 1 {
+		value = values[0]
+	}
+
+	v, err := terraformValue(ctx, value)
+	if err != nil {
+		return types.DynamicNull(), err
+	}
+
+	// Null values are already dynamic.
+	if d, ok := v.(types.Dynamic); ok {
+		return d, nil
+	}
+
+	return types.DynamicValue(v), nil
+}
+
+// jsonResultValue returns the dynamic value of a JSON result.
+func jsonResultValue(ctx context.Context, result string) (types.Dynamic, error) {
+	v, err := decodeJSONValue(result)
+	if err != nil {
+		return types.DynamicNull(), fmt.Errorf("could not decode JSON result: %w", err)
+	}
+
+	return resultValue(ctx, []any{v})
+}
+
+// decodeJSONValue decodes a single JSON value, the numbers are decoded as they are, so they don't lose precision.
+func decodeJSONValue(data string) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(data)))
+	dec.UseNumber()
+
+	var v any
+	err := dec.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+
+	return v, nil
+}
+
+// terraformValue converts a decoded value (using the same data model as decoded JSON) into a Terraform value.
+// The arrays are converted to tuples and the maps to objects, so they can have elements with different types.
+func terraformValue(ctx context.Context, v any) (attr.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return types.DynamicNull(), nil
+	case bool:
+		return types.BoolValue(v), nil
+	case string:
+		return types.StringValue(v), nil
+	case int:
+		return types.NumberValue(new(big.Float).SetInt64(int64(v))), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("unsupported number %v", v)
+		}
+		return types.NumberValue(big.NewFloat(v)), nil
+	case *big.Int:
+		return types.NumberValue(new(big.Float).SetInt(v)), nil
+	case json.Number:
+		f, _, err := big.ParseFloat(v.String(), 10, 512, big.ToNearestEven)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q: %w", v, err)
+		}
+		return types.NumberValue(f), nil
+
+	case []any:
+		elemTypes := make([]attr.Type, 0, len(v))
+		elems := make([]attr.Value, 0, len(v))
+		for i, e := range v {
+			tv, err := terraformValue(ctx, e)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			elemTypes = append(elemTypes, tv.Type(ctx))
+			elems = append(elems, tv)
+		}
+
+		tuple, diags := types.TupleValue(elemTypes, elems)
+		if diags.HasError() {
+			return nil, fmt.Errorf("could not create tuple: %v", diags)
+		}
+		return tuple, nil
+
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		attrTypes := make(map[string]attr.Type, len(v))
+		attrs := make(map[string]attr.Value, len(v))
+		for _, k := range keys {
+			tv, err := terraformValue(ctx, v[k])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			attrTypes[k] = tv.Type(ctx)
+			attrs[k] = tv
+		}
+
+		object, diags := types.ObjectValue(attrTypes, attrs)
+		if diags.HasError() {
+			return nil, fmt.Errorf("could not create object: %v", diags)
+		}
+		return object, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+// inputValue converts a Terraform value into a decoded value, using the same data model as decoded JSON, so
+// it can be used as a processor input. The integer numbers are converted to `int` and the rest to `float64`.
+func inputValue(ctx context.Context, v attr.Value) (any, error) {
+	if v.IsUnknown() {
+		return nil, fmt.Errorf("unknown values are not supported")
+	}
+	if v.IsNull() {
+		return nil, nil
+	}
+
+	switch v := v.(type) {
+	case types.Dynamic:
+		return inputValue(ctx, v.UnderlyingValue())
+	case types.String:
+		return v.ValueString(), nil
+	case types.Bool:
+		return v.ValueBool(), nil
+	case types.Int64:
+		return int(v.ValueInt64()), nil
+	case types.Float64:
+		return v.ValueFloat64(), nil
+	case types.Number:
+		f := v.ValueBigFloat()
+		if f.IsInt() {
+			if i, acc := f.Int64(); acc == big.Exact && i >= math.MinInt && i <= math.MaxInt {
+				return int(i), nil
+			}
+		}
+		n, _ := f.Float64()
+		return n, nil
+	case types.List:
+		return inputValues(ctx, v.Elements())
+	case types.Set:
+		return inputValues(ctx, v.Elements())
+	case types.Tuple:
+		return inputValues(ctx, v.Elements())
+	case types.Map:
+		return inputAttributes(ctx, v.Elements())
+	case types.Object:
+		return inputAttributes(ctx, v.Attributes())
+	}
+
+	return nil, fmt.Errorf("unsupported value type %s", v.Type(ctx))
+}
+
+func inputValues(ctx context.Context, elems []attr.Value) (any, error) {
+	values := make([]any, 0, len(elems))
+	for i, e := range elems {
+		v, err := inputValue(ctx, e)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+func inputAttributes(ctx context.Context, attrs map[string]attr.Value) (any, error) {
+	values := make(map[string]any, len(attrs))
+	for k, a := range attrs {
+		v, err := inputValue(ctx, a)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		values[k] = v
+	}
+
+	return values, nil
+}
+
+// inputString returns the input value as a string, strings are returned as they are and the rest are encoded in JSON.
+func inputString(v any) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(v)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
@@ -0,0 +1,72 @@
+// Package dataprocessor is the API that the Go plugins can use to interact with the provider,
+// the plugins import it as `github.com/slok/terraform-provider-dataprocessor/plugin/dataprocessor`.
+//
+// The package is exposed to the plugins regardless of the sandbox, and it can also be imported
+// from regular Go code, so the plugins can be compiled and tested outside the provider.
+package dataprocessor
+
+import (
+	"context"
+	"sync"
+)
+
+// Severity is the severity of a finding.
+type Severity string
+
+const (
+	// SeverityError findings are shown as Terraform errors and fail the execution.
+	SeverityError Severity = "error"
+	// SeverityWarning findings are shown as Terraform warnings.
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is something the plugin found on the input data (e.g an invalid field on a validation plugin),
+// every finding is shown as an independent Terraform diagnostic.
+type Finding struct {
+	// Severity of the finding, by default error.
+	Severity Severity
+	// Summary is a short description of the finding.
+	Summary string
+	// Detail is the optional long description of the finding.
+	Detail string
+	// Path is the optional location of the finding on the input data (e.g `groups[0].rules[2]`).
+	Path string
+}
+
+// AddFinding adds a finding to the plugin execution of the context. The unknown severities are
+// treated as errors. Outside the provider (e.g plugin tests) it does nothing unless the context
+// has a recorder.
+func AddFinding(ctx context.Context, f Finding) {
+	r, ok := ctx.Value(recorderKey{}).(*Recorder)
+	if !ok {
+		return
+	}
+
+	if f.Severity != SeverityWarning {
+		f.Severity = SeverityError
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.findings = append(r.findings, f)
+}
+
+// Recorder records what the plugins report during an execution, it's safe to use concurrently.
+type Recorder struct {
+	mu       sync.Mutex
+	findings []Finding
+}
+
+// Findings returns the findings added to the recorder, in order.
+func (r *Recorder) Findings() []Finding {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Finding{}, r.findings...)
+}
+
+type recorderKey struct{}
+
+// ContextWithRecorder returns a context that records on r what the plugins using the context report.
+func ContextWithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
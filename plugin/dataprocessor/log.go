@@ -0,0 +1,42 @@
+package dataprocessor
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// The plugin logs are sent to the provider logs (e.g `TF_LOG=debug`), together with the
+// fields of the data source execution (e.g `tf_data_source_type` and `tf_req_id`). Outside the
+// provider they do nothing.
+
+// Debug logs a debug message with optional fields.
+func Debug(ctx context.Context, msg string, fields ...map[string]any) {
+	tflog.Debug(ctx, msg, pluginFields(fields)...)
+}
+
+// Info logs an info message with optional fields.
+func Info(ctx context.Context, msg string, fields ...map[string]any) {
+	tflog.Info(ctx, msg, pluginFields(fields)...)
+}
+
+// Warn logs a warning message with optional fields. Unlike AddWarning, it's not shown to the
+// Terraform user unless the logs are enabled.
+func Warn(ctx context.Context, msg string, fields ...map[string]any) {
+	tflog.Warn(ctx, msg, pluginFields(fields)...)
+}
+
+// Error logs an error message with optional fields, it doesn't fail the execution.
+func Error(ctx context.Context, msg string, fields ...map[string]any) {
+	tflog.Error(ctx, msg, pluginFields(fields)...)
+}
+
+// AddWarning adds a Terraform warning to the plugin execution, it doesn't fail the execution.
+func AddWarning(ctx context.Context, summary, detail string) {
+	AddFinding(ctx, Finding{Severity: SeverityWarning, Summary: summary, Detail: detail})
+}
+
+// pluginFields marks the logs as plugin logs, so they can be told apart from the provider logs.
+func pluginFields(fields []map[string]any) []map[string]any {
+	return append([]map[string]any{{"dataprocessor_plugin": true}}, fields...)
+}